@@ -0,0 +1,476 @@
+package easierweb
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BindSourceQuery  = "query"
+	BindSourceForm   = "form"
+	BindSourceBody   = "body"
+	BindSourcePath   = "path"
+	BindSourceHeader = "header"
+	BindSourceCookie = "cookie"
+)
+
+// BindError request data binding failed (source: query/form/body/path/header/cookie), it is passed to the response handle and the easy handle is not executed
+type BindError struct {
+	Source string `json:"source" xml:"Source" yaml:"source"`
+	Field  string `json:"field,omitempty" xml:"Field,omitempty" yaml:"field,omitempty"`
+	Msg    string `json:"msg" xml:"Msg" yaml:"msg"`
+	Cause  error  `json:"-" xml:"-" yaml:"-"`
+	code   int
+}
+
+func (e *BindError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("bind %s field '%s' error: %s", e.Source, e.Field, e.Msg)
+	}
+	return fmt.Sprintf("bind %s error: %s", e.Source, e.Msg)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Cause
+}
+
+// StatusCode 413 (body too large), 415 (unsupported content type), 422 (field type mismatch) or 400 (malformed data)
+func (e *BindError) StatusCode() int {
+	if e.code == 0 {
+		return http.StatusBadRequest
+	}
+	return e.code
+}
+
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// NewBindError wrap the error as a *BindError, the status code is determined by the error type, return nil if err is nil
+func NewBindError(source string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		return err
+	}
+	e := &BindError{
+		Source: source,
+		Msg:    err.Error(),
+		Cause:  err,
+		code:   http.StatusBadRequest,
+	}
+	var jsonTypeErr *json.UnmarshalTypeError
+	var yamlTypeErr *yaml.TypeError
+	var mapErr *mapstructure.Error
+	if errors.Is(err, ErrBodyTooLarge) {
+		e.code = http.StatusRequestEntityTooLarge
+	} else if errors.Is(err, ErrUnsupportedMediaType) {
+		e.code = http.StatusUnsupportedMediaType
+	} else if errors.As(err, &jsonTypeErr) {
+		e.Field = jsonTypeErr.Field
+		e.code = http.StatusUnprocessableEntity
+	} else if errors.As(err, &yamlTypeErr) {
+		e.code = http.StatusUnprocessableEntity
+	} else if errors.As(err, &mapErr) {
+		if len(mapErr.Errors) > 0 {
+			e.Field = quotedField(mapErr.Errors[0])
+		}
+		e.code = http.StatusUnprocessableEntity
+	}
+	return e
+}
+
+// body parsing failed and the request content type does not match the codec, respond 415
+func newBodyBindError(ctx *Context, name string, codec Codec, err error) error {
+	bindErr := NewBindError(BindSourceBody, err)
+	if bindErr == nil {
+		return nil
+	}
+	contentType := ""
+	if ctx.Request != nil {
+		contentType = strings.ToLower(ctx.Request.Header.Get("Content-Type"))
+	}
+	var e *BindError
+	if contentType != "" && !strings.Contains(contentType, name) && !MatchMediaType(contentType, codec.ContentType()) && errors.As(bindErr, &e) {
+		e.code = http.StatusUnsupportedMediaType
+	}
+	return bindErr
+}
+
+// the aliases of the media types, they are normalized by MediaType
+var mediaTypeAliases = map[string]string{
+	"text/xml":         "application/xml",
+	"application/yaml": "application/x-yaml",
+	"text/yaml":        "application/x-yaml",
+	"text/x-yaml":      "application/x-yaml",
+}
+
+// MediaType the media type of the content type (lower case, without parameters), the aliases are normalized, e.g. text/yaml -> application/x-yaml
+func MediaType(contentType string) string {
+	mt := mediaEssence(contentType)
+	if alias, ok := mediaTypeAliases[mt]; ok {
+		return alias
+	}
+	return mt
+}
+
+// MatchMediaType check whether the content type matches the media type of a codec, with the aliases and the structured syntax suffixes
+// e.g. text/yaml matches application/x-yaml, application/problem+json matches application/json
+func MatchMediaType(contentType, codecType string) bool {
+	mt, target := MediaType(contentType), MediaType(codecType)
+	if mt == target {
+		return true
+	}
+	i := strings.LastIndex(mt, "+")
+	return i >= 0 && MediaType("application/"+mt[i+1:]) == target
+}
+
+func mediaEssence(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mt
+}
+
+// get the field name from mapstructure error message, example: cannot parse 'int64' as int
+func quotedField(msg string) string {
+	start := strings.Index(msg, "'")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(msg[start+1:], "'")
+	if end < 0 {
+		return ""
+	}
+	return msg[start+1 : start+1+end]
+}
+
+// bind request data from multiple sources according to the field tags
+// `path:"id"` `query:"page"` `header:"X-Tenant"` `cookie:"sid"` `form:"name"` and `default:"10"`
+// the default values are set before decoding the body, so the explicit zero values (0, false, "") of the request are kept
+
+var bindSourceTags = []string{BindSourcePath, BindSourceQuery, BindSourceHeader, BindSourceCookie, BindSourceForm}
+
+// Bind set the default values, bind form or json body, uri query parameters (mapstructure tag), and then bind the tagged params
+func (c *Context) Bind(obj any) error {
+	err := c.ParseBody()
+	if err != nil {
+		return err
+	}
+	err = setDefaults(obj)
+	if err != nil {
+		return err
+	}
+	if len(c.Form) > 0 {
+		err := c.BindForm(obj)
+		if err != nil {
+			return err
+		}
+	} else if len(c.Body) > 0 {
+		err := c.BindJSON(obj)
+		if err != nil {
+			return err
+		}
+	}
+	if len(c.Query) > 0 {
+		err := c.BindQuery(obj)
+		if err != nil {
+			return err
+		}
+	}
+	return c.BindParams(obj)
+}
+
+// BindParams bind the fields with path/query/header/cookie/form tags
+func (c *Context) BindParams(obj any) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return NewBindError(BindSourceBody, errors.New("bind object must be a non-nil pointer"))
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	return c.bindParams(value)
+}
+
+func (c *Context) bindParams(value reflect.Value) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldValue := value.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		bound := false
+		for _, source := range bindSourceTags {
+			name := f.Tag.Get(source)
+			if name == "" || name == "-" {
+				continue
+			}
+			values := c.paramValues(source, name)
+			if len(values) == 0 {
+				continue
+			}
+			err := setFieldValues(fieldValue, values)
+			if err != nil {
+				return &BindError{
+					Source: source,
+					Field:  name,
+					Msg:    err.Error(),
+					Cause:  err,
+					code:   http.StatusUnprocessableEntity,
+				}
+			}
+			bound = true
+			break
+		}
+		if bound {
+			continue
+		}
+		// nested structs
+		structValue := fieldValue
+		if structValue.Kind() == reflect.Ptr && !structValue.IsNil() {
+			structValue = structValue.Elem()
+		}
+		if structValue.Kind() == reflect.Struct && structValue.Type() != timeType {
+			err := c.bindParams(structValue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDefaults set the default values of the zero fields (including nested structs), called before decoding the request data
+func setDefaults(obj any) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct || !hasDefaults(value.Elem().Type()) {
+		return nil
+	}
+	return setStructDefaults(value.Elem())
+}
+
+// the struct types with default tags (including nested structs), the fields are not walked per request for the types without them
+var defaultTypes sync.Map
+
+func hasDefaults(t reflect.Type) bool {
+	if has, ok := defaultTypes.Load(t); ok {
+		return has.(bool)
+	}
+	has := typeHasDefaults(t, map[reflect.Type]bool{})
+	defaultTypes.Store(t, has)
+	return has
+}
+
+func typeHasDefaults(t reflect.Type, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if _, has := f.Tag.Lookup("default"); has || typeHasDefaults(f.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func setStructDefaults(value reflect.Value) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldValue := value.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if def, has := f.Tag.Lookup("default"); has {
+			if !fieldValue.IsZero() {
+				continue
+			}
+			err := setFieldValues(fieldValue, defaultValues(fieldValue.Type(), def))
+			if err != nil {
+				return fmt.Errorf("invalid default value of field %s: %s", f.Name, err)
+			}
+			continue
+		}
+		structValue := fieldValue
+		if structValue.Kind() == reflect.Ptr && !structValue.IsNil() {
+			structValue = structValue.Elem()
+		}
+		if structValue.Kind() == reflect.Struct && structValue.Type() != timeType {
+			err := setStructDefaults(structValue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// the default value of slice fields is split by commas
+func defaultValues(t reflect.Type, def string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return strings.Split(def, ",")
+	}
+	return []string{def}
+}
+
+// checkDefaults check the default tags of the request type at registration
+func checkDefaults(t reflect.Type) error {
+	return checkTypeDefaults(t, map[reflect.Type]bool{})
+}
+
+func checkTypeDefaults(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || visited[t] {
+		return nil
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if def, has := f.Tag.Lookup("default"); has {
+			err := setFieldValues(reflect.New(f.Type).Elem(), defaultValues(f.Type, def))
+			if err != nil {
+				return fmt.Errorf("invalid default value of field %s: %s", f.Name, err)
+			}
+			continue
+		}
+		err := checkTypeDefaults(f.Type, visited)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Context) paramValues(source, name string) []string {
+	var value string
+	var has bool
+	var multi []string
+	switch source {
+	case BindSourcePath:
+		value, has = c.Path[name]
+	case BindSourceQuery:
+		value, has = c.Query[name]
+		multi = c.QueryValues[name]
+	case BindSourceForm:
+		value, has = c.Form[name]
+		multi = c.FormValues[name]
+	case BindSourceHeader:
+		name = http.CanonicalHeaderKey(name)
+		value, has = c.Header[name]
+		multi = c.HeaderValues[name]
+	case BindSourceCookie:
+		if c.Request != nil {
+			cookie, err := c.Request.Cookie(name)
+			if err == nil {
+				value, has = cookie.Value, true
+			}
+		}
+	}
+	if !has {
+		return nil
+	}
+	// the single value params are the source of truth, multiple values are used only if they are consistent
+	if len(multi) > 1 && multi[0] == value {
+		return multi
+	}
+	return []string{value}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// set string values to the field, slice fields receive all values, other fields receive the first value
+func setFieldValues(value reflect.Value, values []string) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setFieldValues(value.Elem(), values)
+	}
+	if value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(value.Type(), len(values), len(values))
+		for i, v := range values {
+			err := setFieldValues(slice.Index(i), []string{v})
+			if err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+	s := values[0]
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Slice:
+		value.SetBytes([]byte(s))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type: %s", value.Type())
+	}
+	return nil
+}
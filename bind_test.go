@@ -0,0 +1,190 @@
+package easierweb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// bind error test
+
+func TestBindError(t *testing.T) {
+
+	fmt.Println("\n[TestBindError] start")
+
+	executed := false
+
+	router := New(RouterOptions{
+		RootPath:          "/test/bind",
+		CloseConsolePrint: true,
+	})
+
+	router.EasyPOST("/easy/post", func(ctx *Context, dto routerTestDTO) (*routerTestDTO, error) {
+		executed = true
+		return &dto, nil
+	})
+	router.EasyGET("/easy/get", func(ctx *Context, dto routerTestDTO) (*routerTestDTO, error) {
+		executed = true
+		return &dto, nil
+	})
+
+	cases := []struct {
+		method      string
+		uri         string
+		contentType string
+		body        string
+		code        int
+		source      string
+	}{
+		{"POST", "/easy/post", "application/json", "{\"int\":", http.StatusBadRequest, BindSourceBody},
+		{"POST", "/easy/post", "application/json", "{\"int\":\"abc\"}", http.StatusUnprocessableEntity, BindSourceBody},
+		{"POST", "/easy/post", "application/xml", "<int>1</int>", http.StatusUnsupportedMediaType, BindSourceBody},
+		{"GET", "/easy/get?int=abc", "", "", http.StatusUnprocessableEntity, BindSourceQuery},
+	}
+
+	for _, c := range cases {
+		executed = false
+		req := httptest.NewRequest(c.method, "/test/bind"+c.uri, strings.NewReader(c.body))
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		fmt.Printf("[TestBindError] %s %s response code: %v, data -> %s \n", c.method, c.uri, res.Code, res.Body.String())
+		if res.Code != c.code {
+			t.Fatal("bind error status code error:", c.uri, res.Code)
+		}
+		if !strings.Contains(res.Body.String(), "\"source\":\""+c.source+"\"") {
+			t.Fatal("bind error source error:", c.uri, res.Body.String())
+		}
+		if executed {
+			t.Fatal("handle executed after bind failure:", c.uri)
+		}
+	}
+
+	ctx := &Context{Query: Params{"int64": "abc"}}
+	err := ctx.BindQuery(&routerTestDTO{})
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) || bindErr.Field != "int64" || bindErr.Source != BindSourceQuery {
+		t.Fatal("bind error field error:", err)
+	}
+
+	fmt.Println("\n[TestBindError] end")
+}
+
+// multi-source binding test
+
+func TestBindParams(t *testing.T) {
+
+	fmt.Println("\n[TestBindParams] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/bind",
+		CloseConsolePrint: true,
+	})
+
+	var request bindTestDTO
+	router.EasyPOST("/params/:id", func(ctx *Context, dto bindTestDTO) {
+		request = dto
+	})
+
+	req := httptest.NewRequest("POST", "/test/bind/params/42?page=2", strings.NewReader("{\"name\":\"hello\",\"limit\":0}"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "tenant")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "session"})
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	fmt.Printf("[TestBindParams] response code: %v, request -> %+v \n", res.Code, request)
+	if res.Code != http.StatusNoContent {
+		t.Fatal("bind params status code error:", res.Code)
+	}
+	// the explicit zero value of the body is not overwritten by the default value
+	expected := bindTestDTO{ID: 42, Page: 2, Size: 10, Tenant: "tenant", Session: "session", Name: "hello", Tags: []string{"a", "b"}, Limit: 0}
+	if request.ID != expected.ID || request.Page != expected.Page || request.Size != expected.Size ||
+		request.Tenant != expected.Tenant || request.Session != expected.Session || request.Name != expected.Name ||
+		len(request.Tags) != 2 || request.Limit != expected.Limit {
+		t.Fatal("bind params error:", request)
+	}
+
+	req = httptest.NewRequest("POST", "/test/bind/params/abc", nil)
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	fmt.Printf("[TestBindParams] response code: %v, data -> %s \n", res.Code, res.Body.String())
+	if res.Code != http.StatusUnprocessableEntity || !strings.Contains(res.Body.String(), "\"source\":\"path\"") {
+		t.Fatal("bind params error response error:", res.Code)
+	}
+
+	// the invalid default values panic at registration
+	func() {
+		defer func() {
+			err := recover()
+			fmt.Println("[TestBindParams] invalid default:", err)
+			if err == nil {
+				t.Error("the invalid default value is not checked at registration")
+			}
+		}()
+		router.EasyGET("/invalid", func(ctx *Context, dto struct {
+			Page int `query:"page" default:"first"`
+		}) {
+		})
+	}()
+
+	fmt.Println("\n[TestBindParams] end")
+}
+
+type bindTestDTO struct {
+	ID      int64    `path:"id"`
+	Page    int      `query:"page" default:"1"`
+	Size    int      `query:"size" default:"10"`
+	Tenant  string   `header:"X-Tenant"`
+	Session string   `cookie:"sid"`
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags" default:"a,b"`
+	Limit   int      `json:"limit" default:"20"`
+}
+
+// multi-value params binding test
+
+func TestBindMultiParams(t *testing.T) {
+
+	fmt.Println("\n[TestBindMultiParams] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/bind",
+		CloseConsolePrint: true,
+	})
+
+	var request bindTestMultiDTO
+	var ids []int
+	router.EasyGET("/multi", func(ctx *Context, dto bindTestMultiDTO) {
+		request = dto
+		ids = ctx.QueryValues.Ints("id")
+	})
+
+	req := httptest.NewRequest("GET", "/test/bind/multi?tag=a&tag=b&id=1&id=2&id=3&name=hello&name=world&page=2&page=3", nil)
+	req.Header.Add("X-Role", "admin")
+	req.Header.Add("X-Role", "user")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	fmt.Printf("[TestBindMultiParams] response code: %v, request -> %+v, ids -> %v \n", res.Code, request, ids)
+	if len(request.Tags) != 2 || request.Tags[1] != "b" || len(request.IDs) != 3 || request.IDs[2] != 3 ||
+		request.Name != "hello" || request.Page != 2 || len(request.Roles) != 2 || request.Roles[1] != "user" || len(ids) != 3 {
+		t.Fatal("bind multi params error:", request)
+	}
+
+	fmt.Println("\n[TestBindMultiParams] end")
+}
+
+type bindTestMultiDTO struct {
+	Tags []string `mapstructure:"tag"`
+	IDs  []int64  `query:"id"`
+	// the repeated keys are bound to the scalar fields as the first value
+	Name  string   `mapstructure:"name"`
+	Page  int      `mapstructure:"page"`
+	Roles []string `header:"X-Role"`
+}
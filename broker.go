@@ -0,0 +1,334 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// server-sent events broker, the events published to a topic are pushed to all the subscribers of the topic
+
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDrop drop the events when the buffer of the subscriber is full
+	SlowConsumerDrop SlowConsumerPolicy = iota
+	// SlowConsumerDisconnect disconnect the subscriber when its buffer is full, the client can reconnect and replay the missed events
+	SlowConsumerDisconnect
+)
+
+var (
+	ErrSlowConsumer  = errors.New("sse subscriber is disconnected: slow consumer")
+	ErrBrokerClosed  = errors.New("sse broker is closed")
+	ErrBrokerNoTopic = errors.New("sse subscriber has no topic")
+)
+
+type BrokerOptions struct {
+	// buffered events of each subscriber, default: 64
+	BufferSize int
+	// what to do when the buffer of a subscriber is full, default: SlowConsumerDrop
+	SlowConsumer SlowConsumerPolicy
+	// recent events kept for replay of each topic, default: 128, negative to disable
+	ReplaySize int
+	// the topics without subscribers are removed (with their replay buffers) when no event is published within the ttl
+	// default: 5 minutes, negative to keep them
+	TopicTTL time.Duration
+}
+
+type Broker struct {
+	bufferSize   int
+	slowConsumer SlowConsumerPolicy
+	replaySize   int
+	topicTTL     time.Duration
+	topics       map[string]*brokerTopic
+	// sequence of the published events, the ids of the events are namespace-sequence if not set
+	seq       uint64
+	namespace string
+	lastSweep time.Time
+	closed    bool
+	mutex     sync.Mutex
+}
+
+type brokerTopic struct {
+	subscribers map[*subscriber]struct{}
+	// ring buffer of the recent events
+	replay []brokerEvent
+	next   int
+	// the last publish or unsubscribe
+	active time.Time
+}
+
+type brokerEvent struct {
+	seq   uint64
+	event SSEEvent
+}
+
+type subscriber struct {
+	events    chan SSEEvent
+	done      chan struct{}
+	err       error
+	closeOnce sync.Once
+}
+
+func (s *subscriber) close(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+func NewBroker(opts ...BrokerOptions) *Broker {
+	b := &Broker{
+		bufferSize: 64,
+		replaySize: 128,
+		topicTTL:   5 * time.Minute,
+		topics:     make(map[string]*brokerTopic),
+		// the generated ids of another broker (or this broker before a restart) are not mistaken for the sequences of this broker
+		namespace: strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	for _, v := range opts {
+		if v.BufferSize > 0 {
+			b.bufferSize = v.BufferSize
+		}
+		b.slowConsumer = v.SlowConsumer
+		if v.ReplaySize != 0 {
+			b.replaySize = v.ReplaySize
+		}
+		if v.TopicTTL != 0 {
+			b.topicTTL = v.TopicTTL
+		}
+	}
+	return b
+}
+
+// Publish push the event to the subscribers of the topic and keep it for replay, return the id of the event
+// the id is generated by the broker (namespace-sequence) if it is not set
+func (b *Broker) Publish(topic string, event SSEEvent) string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.seq++
+	if event.ID == "" {
+		event.ID = b.namespace + "-" + strconv.FormatUint(b.seq, 10)
+	}
+	now := time.Now()
+	b.sweep(now)
+	t, has := b.topics[topic]
+	if !has && b.replaySize <= 0 {
+		return event.ID
+	}
+	if !has {
+		t = b.topic(topic)
+	}
+	t.active = now
+	if b.replaySize > 0 {
+		if len(t.replay) < b.replaySize {
+			t.replay = append(t.replay, brokerEvent{seq: b.seq, event: event})
+		} else {
+			t.replay[t.next] = brokerEvent{seq: b.seq, event: event}
+			t.next = (t.next + 1) % b.replaySize
+		}
+	}
+	for s := range t.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			if b.slowConsumer == SlowConsumerDisconnect {
+				b.remove(s)
+				s.close(ErrSlowConsumer)
+			}
+		}
+	}
+	return event.ID
+}
+
+// PublishJSON publish an event with the json data
+func (b *Broker) PublishJSON(topic string, event string, obj any) (string, error) {
+	marshal, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return b.Publish(topic, SSEEvent{Event: event, Data: string(marshal)}), nil
+}
+
+// Subscribe push the events of the topics to the sse connection, it blocks until the client disconnects or the broker is closed
+// if the client sends the Last-Event-ID header, the missed events kept in the replay buffers are pushed first
+// nothing is replayed if the id is unknown (neither in the replay buffers nor generated by this broker)
+// return ErrSlowConsumer if the subscriber is disconnected by the SlowConsumerDisconnect policy
+func (b *Broker) Subscribe(ctx *Context, topics ...string) error {
+	if len(topics) == 0 {
+		return ErrBrokerNoTopic
+	}
+	s, replay, err := b.subscribe(ctx.LastEventID(), topics)
+	if err != nil {
+		return err
+	}
+	defer b.unsubscribe(s)
+	// send the response headers, so the client is connected before the first event
+	err = ctx.pushRaw("")
+	if err != nil {
+		return err
+	}
+	for _, event := range replay {
+		err = ctx.PushEvent(event)
+		if err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return s.err
+		case event := <-s.events:
+			err = ctx.PushEvent(event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Handle an sse handle subscribing the topics, example: router.SSE("/news", broker.Handle("news"))
+func (b *Broker) Handle(topics ...string) Handle {
+	return func(ctx *Context) {
+		err := b.Subscribe(ctx, topics...)
+		if err != nil && ctx.Logger != nil {
+			ctx.Logger.Warn("sse subscriber exit: " + err.Error())
+		}
+	}
+}
+
+// Subscribers the number of the subscribers of the topic
+func (b *Broker) Subscribers(topic string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if t, has := b.topics[topic]; has {
+		return len(t.subscribers)
+	}
+	return 0
+}
+
+// Close end all the subscriptions, the events published after closing are kept for replay only
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed = true
+	for _, t := range b.topics {
+		for s := range t.subscribers {
+			s.close(nil)
+		}
+		t.subscribers = make(map[*subscriber]struct{})
+	}
+}
+
+// the replay events are collected with the registration, so no event is missed or pushed twice
+func (b *Broker) subscribe(lastEventID string, topics []string) (*subscriber, []SSEEvent, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return nil, nil, ErrBrokerClosed
+	}
+	b.sweep(time.Now())
+	s := &subscriber{
+		events: make(chan SSEEvent, b.bufferSize),
+		done:   make(chan struct{}),
+	}
+	var replay []brokerEvent
+	if lastEventID != "" {
+		if last, ok := b.lastSeq(lastEventID, topics); ok {
+			for _, name := range topics {
+				t, has := b.topics[name]
+				if !has {
+					continue
+				}
+				for _, e := range t.replay {
+					if e.seq > last {
+						replay = append(replay, e)
+					}
+				}
+			}
+			sort.Slice(replay, func(i, j int) bool {
+				return replay[i].seq < replay[j].seq
+			})
+		}
+	}
+	for _, name := range topics {
+		b.topic(name).subscribers[s] = struct{}{}
+	}
+	events := make([]SSEEvent, len(replay))
+	for i, e := range replay {
+		events[i] = e.event
+	}
+	return s, events, nil
+}
+
+// lastSeq find the sequence of the last received event in the replay buffers
+// the sequence of an id generated by this broker is parsed, even if the event is out of the replay buffers
+func (b *Broker) lastSeq(lastEventID string, topics []string) (uint64, bool) {
+	for _, name := range topics {
+		t, has := b.topics[name]
+		if !has {
+			continue
+		}
+		for _, e := range t.replay {
+			if e.event.ID == lastEventID {
+				return e.seq, true
+			}
+		}
+	}
+	seq, found := strings.CutPrefix(lastEventID, b.namespace+"-")
+	if !found {
+		return 0, false
+	}
+	last, err := strconv.ParseUint(seq, 10, 64)
+	return last, err == nil
+}
+
+func (b *Broker) unsubscribe(s *subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.remove(s)
+}
+
+func (b *Broker) remove(s *subscriber) {
+	now := time.Now()
+	for name, t := range b.topics {
+		if _, has := t.subscribers[s]; !has {
+			continue
+		}
+		delete(t.subscribers, s)
+		t.active = now
+		if len(t.subscribers) == 0 && len(t.replay) == 0 {
+			delete(b.topics, name)
+		}
+	}
+}
+
+// sweep remove the topics without subscribers that are inactive for the ttl, it runs at most once per ttl
+func (b *Broker) sweep(now time.Time) {
+	if b.topicTTL <= 0 || now.Sub(b.lastSweep) < b.topicTTL {
+		return
+	}
+	b.lastSweep = now
+	for name, t := range b.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.active) > b.topicTTL {
+			delete(b.topics, name)
+		}
+	}
+}
+
+func (b *Broker) topic(name string) *brokerTopic {
+	t, has := b.topics[name]
+	if !has {
+		t = &brokerTopic{
+			subscribers: make(map[*subscriber]struct{}),
+		}
+		b.topics[name] = t
+	}
+	return t
+}
@@ -0,0 +1,169 @@
+package easierweb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dpwgc/easierweb/easierwebtest"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sse broker test
+
+func TestBroker(t *testing.T) {
+
+	fmt.Println("\n[TestBroker] start")
+
+	broker := NewBroker(BrokerOptions{ReplaySize: 2})
+	defer broker.Close()
+
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+	})
+	router.SSE("/news", broker.Handle("news", "sport"))
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	// the first event is out of the replay buffer, its id is generated by the broker, so the sequence is parsed
+	first := broker.Publish("news", SSEEvent{Data: "a"})
+	broker.Publish("sport", SSEEvent{Data: "b"})
+	broker.Publish("news", SSEEvent{Data: "c"})
+	broker.Publish("weather", SSEEvent{Data: "x"})
+
+	stream, err := tc.GET("/news").Header("Last-Event-ID", first).SSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	waitSubscribers(t, broker, "news", 1)
+
+	_, err = broker.PublishJSON("sport", "score", map[string]int{"home": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"2 b", "3 c", `5 {"home":1}`}
+	for _, e := range expected {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("[TestBroker] event:", *event)
+		if event.ID[strings.LastIndex(event.ID, "-")+1:]+" "+event.Data != e {
+			t.Errorf("unexpected event: %+v, expected: %s", *event, e)
+		}
+	}
+	_ = stream.Close()
+	waitSubscribers(t, broker, "news", 0)
+
+	// the unknown ids are not mistaken for the sequences, nothing is replayed
+	stream, err = tc.GET("/news").Header("Last-Event-ID", "1").SSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	waitSubscribers(t, broker, "news", 1)
+	broker.Publish("news", SSEEvent{Data: "d"})
+	event, err := stream.Next()
+	fmt.Println("[TestBroker] event after unknown id:", event, err)
+	if err != nil || event.Data != "d" {
+		t.Errorf("unexpected event after unknown id: %v %v", event, err)
+	}
+	_ = stream.Close()
+	waitSubscribers(t, broker, "news", 0)
+}
+
+func TestBrokerTopicTTL(t *testing.T) {
+
+	fmt.Println("\n[TestBrokerTopicTTL] start")
+
+	broker := NewBroker(BrokerOptions{TopicTTL: 20 * time.Millisecond})
+	defer broker.Close()
+
+	broker.Publish("a", SSEEvent{Data: "a"})
+	// the topics with subscribers are kept
+	_, _, _ = broker.subscribe("", []string{"b"})
+	time.Sleep(50 * time.Millisecond)
+	broker.Publish("c", SSEEvent{Data: "c"})
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	// the lookups do not create topics
+	broker.lastSeq("1", []string{"d"})
+	_, hasA := broker.topics["a"]
+	_, hasB := broker.topics["b"]
+	_, hasC := broker.topics["c"]
+	_, hasD := broker.topics["d"]
+	fmt.Println("[TestBrokerTopicTTL] topics:", len(broker.topics))
+	if hasA || !hasB || !hasC || hasD {
+		t.Errorf("unexpected topics, a: %v, b: %v, c: %v, d: %v", hasA, hasB, hasC, hasD)
+	}
+}
+
+func TestBrokerSlowConsumer(t *testing.T) {
+
+	fmt.Println("\n[TestBrokerSlowConsumer] start")
+
+	broker := NewBroker(BrokerOptions{BufferSize: 1, SlowConsumer: SlowConsumerDisconnect})
+	defer broker.Close()
+
+	exit := make(chan error, 1)
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+	})
+	router.SSE("/news", func(ctx *Context) {
+		exit <- broker.Subscribe(ctx, "news")
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	stream, err := tc.GET("/news").SSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	waitSubscribers(t, broker, "news", 1)
+
+	// the stream is not read, the writes of the in-memory connection block
+	for i := 0; i < 10; i++ {
+		broker.Publish("news", SSEEvent{Data: fmt.Sprint(i)})
+	}
+	if n := broker.Subscribers("news"); n != 0 {
+		t.Fatalf("the slow consumer is not disconnected: %d", n)
+	}
+
+	received := 0
+	for {
+		_, err = stream.Next()
+		if err != nil {
+			break
+		}
+		received++
+	}
+	fmt.Println("[TestBrokerSlowConsumer] received:", received, "error:", err)
+	if !errors.Is(err, io.EOF) || received >= 10 {
+		t.Errorf("unexpected stream end: %d %v", received, err)
+	}
+	select {
+	case err = <-exit:
+		if !errors.Is(err, ErrSlowConsumer) {
+			t.Errorf("unexpected subscribe error: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("the subscriber does not exit")
+	}
+}
+
+func waitSubscribers(t *testing.T, broker *Broker, topic string, n int) {
+	t.Helper()
+	for i := 0; i < 300; i++ {
+		if broker.Subscribers(topic) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the number of the subscribers of %s is not %d", topic, n)
+}
@@ -0,0 +1,85 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec serialization format of request data, response data and websocket messages
+type Codec interface {
+	ContentType() string
+	Marshal(obj any) ([]byte, error)
+	Unmarshal(data []byte, obj any) error
+}
+
+const (
+	CodecJSON = "json"
+	CodecYAML = "yaml"
+	CodecXML  = "xml"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                  { return "application/json; charset=utf-8" }
+func (jsonCodec) Marshal(obj any) ([]byte, error)      { return json.Marshal(obj) }
+func (jsonCodec) Unmarshal(data []byte, obj any) error { return json.Unmarshal(data, obj) }
+
+type yamlCodec struct{}
+
+func (yamlCodec) ContentType() string                  { return "application/x-yaml; charset=utf-8" }
+func (yamlCodec) Marshal(obj any) ([]byte, error)      { return yaml.Marshal(obj) }
+func (yamlCodec) Unmarshal(data []byte, obj any) error { return yaml.Unmarshal(data, obj) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                  { return "application/xml; charset=utf-8" }
+func (xmlCodec) Marshal(obj any) ([]byte, error)      { return xml.Marshal(obj) }
+func (xmlCodec) Unmarshal(data []byte, obj any) error { return xml.Unmarshal(data, obj) }
+
+// the default codecs, they can be replaced globally (before creating routers), or per router by RouterOptions.Codecs and router.RegisterCodec
+var (
+	JSONCodec Codec = jsonCodec{}
+	YAMLCodec Codec = yamlCodec{}
+	XMLCodec  Codec = xmlCodec{}
+)
+
+func defaultCodecs() map[string]Codec {
+	return map[string]Codec{
+		CodecJSON: JSONCodec,
+		CodecYAML: YAMLCodec,
+		CodecXML:  XMLCodec,
+	}
+}
+
+// RegisterCodec add or replace a codec, used by ctx.BindCodec/WriteCodec/SendCodec/ReceiveCodec and the JSON/YAML/XML functions
+// the codecs are copied on write, so it is safe to register while serving, the requests in progress keep the previous codecs
+func (r *Router) RegisterCodec(name string, codec Codec) *Router {
+	r.codecMutex.Lock()
+	defer r.codecMutex.Unlock()
+	old := *r.codecs.Load()
+	codecs := make(map[string]Codec, len(old)+1)
+	for k, v := range old {
+		codecs[k] = v
+	}
+	codecs[name] = codec
+	r.codecs.Store(&codecs)
+	return r
+}
+
+// Codec get the codec registered on the router by name, return nil if it does not exist
+func (c *Context) Codec(name string) Codec {
+	if c.codecs != nil {
+		return c.codecs[name]
+	}
+	return defaultCodecs()[name]
+}
+
+func (c *Context) mustCodec(name string) Codec {
+	codec := c.Codec(name)
+	if codec == nil {
+		panic(fmt.Errorf("codec %s does not exist", name))
+	}
+	return codec
+}
@@ -0,0 +1,84 @@
+package easierweb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// codec registry test
+
+type codecTestCodec struct{}
+
+func (codecTestCodec) ContentType() string { return "text/x-line" }
+
+func (codecTestCodec) Marshal(obj any) ([]byte, error) {
+	return []byte(obj.(*codecTestLine).Text), nil
+}
+
+func (codecTestCodec) Unmarshal(data []byte, obj any) error {
+	obj.(*codecTestLine).Text = strings.ToUpper(string(data))
+	return nil
+}
+
+type codecTestLine struct {
+	Text string
+}
+
+func TestCodec(t *testing.T) {
+
+	fmt.Println("\n[TestCodec] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/codec",
+		CloseConsolePrint: true,
+	}).RegisterCodec("line", codecTestCodec{})
+
+	router.POST("/line", func(ctx *Context) {
+		line := &codecTestLine{}
+		err := ctx.BindCodec("line", line)
+		if err != nil {
+			panic(err)
+		}
+		ctx.WriteCodec(http.StatusOK, "line", line)
+	})
+	router.POST("/unknown", func(ctx *Context) {
+		err := ctx.BindCodec("msgpack", &codecTestLine{})
+		ctx.WriteString(err.(*BindError).StatusCode(), err.Error())
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/test/codec/line", strings.NewReader("hello")))
+	fmt.Println("[TestCodec] line:", res.Code, res.Header().Get("Content-Type"), res.Body.String())
+	if res.Code != http.StatusOK || res.Body.String() != "HELLO" || res.Header().Get("Content-Type") != "text/x-line" {
+		t.Errorf("unexpected line codec response: %d %s", res.Code, res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/test/codec/unknown", strings.NewReader("hello")))
+	fmt.Println("[TestCodec] unknown:", res.Code, res.Body.String())
+	if res.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("unknown codec status code %d, expected 415", res.Code)
+	}
+
+	// the codecs can be registered while serving (go test -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			router.RegisterCodec(fmt.Sprint("line", i), codecTestCodec{})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/test/codec/line", strings.NewReader("hello")))
+	}
+	<-done
+
+	data := Data{}
+	err := data.SaveCodec(JSONCodec, map[string]int{"a": 1})
+	if err != nil || string(data) != "{\"a\":1}" {
+		t.Errorf("unexpected saved data: %s %v", data, err)
+	}
+}
@@ -1,12 +1,11 @@
 package easierweb
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"golang.org/x/net/websocket"
-	"gopkg.in/yaml.v3"
 	"io"
 	"log/slog"
 	"mime/multipart"
@@ -14,6 +13,8 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +24,9 @@ type Context struct {
 	Path           Params
 	Query          Params
 	Form           Params
+	HeaderValues   MultiParams
+	QueryValues    MultiParams
+	FormValues     MultiParams
 	Body           Data
 	Code           int
 	Result         Data
@@ -35,6 +39,22 @@ type Context struct {
 	handles        []Handle
 	written        bool
 	closed         bool
+	maxBodySize    int64
+	formMaxMemory  int64
+	bodyParsed     bool
+	bodyStreamed   bool
+	bodyErr        error
+	codecs         map[string]Codec
+	requestHandle  RequestHandle
+	responseHandle ResponseHandle
+	errorHandle    ErrorHandle
+	pushMutex      *sync.Mutex
+	closeHooks     []func()
+	wsReadTimeout  time.Duration
+	wsWriteTimeout time.Duration
+	wsPushOnly     bool
+	// the websocket connection is closed by another goroutine (e.g. the hub disconnects a slow connection)
+	wsClosed *atomic.Bool
 }
 
 func (c *Context) Next() {
@@ -52,6 +72,9 @@ func (c *Context) Abort() {
 // POST Form File
 
 func (c *Context) FileKeys() []string {
+	if c.ParseBody() != nil || c.Request.MultipartForm == nil {
+		return nil
+	}
 	files := c.Request.MultipartForm.File
 	var ks = make([]string, 0, len(files))
 	for k := range files {
@@ -61,6 +84,10 @@ func (c *Context) FileKeys() []string {
 }
 
 func (c *Context) GetFile(key string) (multipart.File, error) {
+	err := c.ParseBody()
+	if err != nil {
+		return nil, err
+	}
 	file, _, err := c.Request.FormFile(key)
 	if err != nil {
 		return nil, err
@@ -68,73 +95,183 @@ func (c *Context) GetFile(key string) (multipart.File, error) {
 	return file, nil
 }
 
-// Query/Form/Path/Header Params Bind
+// Query/Form/Path/Header Params Bind (keys with multiple values can be bound to slice fields)
 
 func (c *Context) BindQuery(obj any) error {
-	return c.Query.Bind(obj)
+	return NewBindError(BindSourceQuery, bindValues(c.QueryValues.values(c.Query), obj))
 }
 
 func (c *Context) BindForm(obj any) error {
-	return c.Form.Bind(obj)
+	err := c.ParseBody()
+	if err != nil {
+		return err
+	}
+	return NewBindError(BindSourceForm, bindValues(c.FormValues.values(c.Form), obj))
 }
 
 func (c *Context) BindPath(obj any) error {
-	return c.Path.Bind(obj)
+	return NewBindError(BindSourcePath, c.Path.Bind(obj))
 }
 
 func (c *Context) BindHeader(obj any) error {
-	return c.Header.Bind(obj)
+	return NewBindError(BindSourceHeader, bindValues(c.HeaderValues.values(c.Header), obj))
+}
+
+// POST Body Parse
+
+// ParseBody parse the request body into ctx.Body or ctx.Form (by content type), it is executed only once
+// the body is parsed before the handles run, unless the lazy parsing is enabled
+func (c *Context) ParseBody() error {
+	if c.bodyParsed {
+		return c.bodyErr
+	}
+	c.bodyParsed = true
+	c.bodyErr = c.parseBody()
+	return c.bodyErr
+}
+
+func (c *Context) ReadBody() (Data, error) {
+	err := c.ParseBody()
+	return c.Body, err
+}
+
+func (c *Context) ReadForm() (Params, error) {
+	err := c.ParseBody()
+	return c.Form, err
+}
+
+var ErrBodyConsumed = errors.New("request body consumed by the body reader")
+
+// BodyReader get the raw request body reader (size limited) to process the body incrementally
+// if the body has been parsed, return the reader of the parsed body data
+// if the body has not been parsed, it is consumed as a stream, and then ParseBody and the body bindings return ErrBodyConsumed
+func (c *Context) BodyReader() io.Reader {
+	if c.bodyStreamed {
+		return c.Request.Body
+	}
+	if c.bodyParsed {
+		return bytes.NewReader(c.Body)
+	}
+	c.bodyParsed = true
+	c.bodyStreamed = true
+	c.bodyErr = ErrBodyConsumed
+	return c.Request.Body
+}
+
+func (c *Context) parseBody() error {
+	req := c.Request
+	if req == nil || req.Body == nil {
+		return nil
+	}
+	if c.maxBodySize > 0 && req.ContentLength > c.maxBodySize {
+		return NewBindError(BindSourceBody, ErrBodyTooLarge)
+	}
+	var err error
+	contentType := strings.ToLower(req.Header.Get("Content-Type"))
+	if strings.Contains(contentType, "multipart/form-data") {
+		err = req.ParseMultipartForm(c.formMaxMemory)
+	} else if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+		err = req.ParseForm()
+	} else {
+		c.Body, err = io.ReadAll(req.Body)
+	}
+	if err != nil {
+		if body, ok := req.Body.(*limitedBody); ok && body.exceeded {
+			return NewBindError(BindSourceBody, ErrBodyTooLarge)
+		}
+		return NewBindError(BindSourceBody, err)
+	}
+	if len(req.PostForm) > 0 {
+		c.FormValues = MultiParams(req.PostForm)
+		c.Form = make(map[string]string, len(req.PostForm))
+		for k, v := range req.PostForm {
+			if len(v) > 0 {
+				c.Form[k] = v[0]
+			}
+		}
+	}
+	return nil
+}
+
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// limitedBody the request body reader with size limit
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		return n, ErrBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
 }
 
 // POST Body Bind
 
 func (c *Context) BindJSON(obj any) error {
-	return c.Body.ParseJSON(obj)
+	return c.BindCodec(CodecJSON, obj)
 }
 
 func (c *Context) BindYAML(obj any) error {
-	return c.Body.ParseYAML(obj)
+	return c.BindCodec(CodecYAML, obj)
 }
 
 func (c *Context) BindXML(obj any) error {
-	return c.Body.ParseXML(obj)
+	return c.BindCodec(CodecXML, obj)
+}
+
+// BindCodec parse the body with the codec registered on the router by name
+func (c *Context) BindCodec(name string, obj any) error {
+	err := c.ParseBody()
+	if err != nil {
+		return err
+	}
+	codec := c.Codec(name)
+	if codec == nil {
+		return NewBindError(BindSourceBody, ErrUnsupportedMediaType)
+	}
+	return newBodyBindError(c, name, codec, codec.Unmarshal(c.Body, obj))
 }
 
 // Result Write
 
 func (c *Context) WriteJSON(code int, obj any) {
-	if c.written {
-		return
-	}
-	marshal, err := json.Marshal(obj)
-	if err != nil {
-		panic(err)
-	}
-	c.AddContentType("application/json; charset=utf-8")
-	c.Write(code, marshal)
+	c.WriteCodec(code, CodecJSON, obj)
 }
 
 func (c *Context) WriteYAML(code int, obj any) {
-	if c.written {
-		return
-	}
-	marshal, err := yaml.Marshal(obj)
-	if err != nil {
-		panic(err)
-	}
-	c.AddContentType("application/x-yaml; charset=utf-8")
-	c.Write(code, marshal)
+	c.WriteCodec(code, CodecYAML, obj)
 }
 
 func (c *Context) WriteXML(code int, obj any) {
+	c.WriteCodec(code, CodecXML, obj)
+}
+
+// WriteCodec serialize the object with the codec registered on the router by name
+func (c *Context) WriteCodec(code int, name string, obj any) {
 	if c.written {
 		return
 	}
-	marshal, err := xml.Marshal(obj)
+	codec := c.mustCodec(name)
+	marshal, err := codec.Marshal(obj)
 	if err != nil {
 		panic(err)
 	}
-	c.AddContentType("application/xml; charset=utf-8")
+	c.AddContentType(codec.ContentType())
 	c.Write(code, marshal)
 }
 
@@ -194,9 +331,11 @@ func (c *Context) Write(code int, data []byte) {
 		return
 	}
 	c.ResponseWriter.WriteHeader(code)
-	_, err := c.ResponseWriter.Write(data)
-	if err != nil {
-		panic(err)
+	if len(data) > 0 {
+		_, err := c.ResponseWriter.Write(data)
+		if err != nil {
+			panic(err)
+		}
 	}
 	c.Code = code
 	c.Result = data
@@ -230,30 +369,36 @@ func (c *Context) AddHeader(key, value string) {
 // WS Receive
 
 func (c *Context) ReceiveJSON(obj any) error {
-	return websocket.JSON.Receive(c.WebsocketConn, obj)
+	return c.ReceiveCodec(CodecJSON, obj)
 }
 
 func (c *Context) ReceiveYAML(obj any) error {
-	var buf string
-	err := websocket.Message.Receive(c.WebsocketConn, &buf)
-	if err != nil {
-		return err
-	}
-	return yaml.Unmarshal([]byte(buf), obj)
+	return c.ReceiveCodec(CodecYAML, obj)
 }
 
 func (c *Context) ReceiveXML(obj any) error {
-	var buf string
-	err := websocket.Message.Receive(c.WebsocketConn, &buf)
+	return c.ReceiveCodec(CodecXML, obj)
+}
+
+func (c *Context) ReceiveCodec(name string, obj any) error {
+	codec := c.Codec(name)
+	if codec == nil {
+		return fmt.Errorf("codec %s does not exist", name)
+	}
+	buf, err := c.Receive()
 	if err != nil {
 		return err
 	}
-	return xml.Unmarshal([]byte(buf), obj)
+	return codec.Unmarshal(buf, obj)
 }
 
 func (c *Context) ReceiveString() (string, error) {
 	var buf string
-	err := websocket.Message.Receive(c.WebsocketConn, &buf)
+	err := c.setReadDeadline()
+	if err != nil {
+		return "", err
+	}
+	err = websocket.Message.Receive(c.WebsocketConn, &buf)
 	if err != nil {
 		return "", err
 	}
@@ -262,7 +407,11 @@ func (c *Context) ReceiveString() (string, error) {
 
 func (c *Context) Receive() ([]byte, error) {
 	var buf []byte
-	err := websocket.Message.Receive(c.WebsocketConn, &buf)
+	err := c.setReadDeadline()
+	if err != nil {
+		return nil, err
+	}
+	err = websocket.Message.Receive(c.WebsocketConn, &buf)
 	if err != nil {
 		return nil, err
 	}
@@ -272,23 +421,23 @@ func (c *Context) Receive() ([]byte, error) {
 // WS Send
 
 func (c *Context) SendJSON(obj any) error {
-	marshal, err := json.Marshal(obj)
-	if err != nil {
-		return err
-	}
-	return c.Send(marshal)
+	return c.SendCodec(CodecJSON, obj)
 }
 
 func (c *Context) SendYAML(obj any) error {
-	marshal, err := yaml.Marshal(obj)
-	if err != nil {
-		return err
-	}
-	return c.Send(marshal)
+	return c.SendCodec(CodecYAML, obj)
 }
 
 func (c *Context) SendXML(obj any) error {
-	marshal, err := xml.Marshal(obj)
+	return c.SendCodec(CodecXML, obj)
+}
+
+func (c *Context) SendCodec(name string, obj any) error {
+	codec := c.Codec(name)
+	if codec == nil {
+		return fmt.Errorf("codec %s does not exist", name)
+	}
+	marshal, err := codec.Marshal(obj)
 	if err != nil {
 		return err
 	}
@@ -300,7 +449,11 @@ func (c *Context) SendString(text string) error {
 }
 
 func (c *Context) Send(msg []byte) error {
-	_, err := c.WebsocketConn.Write(msg)
+	err := c.setWriteDeadline()
+	if err != nil {
+		return err
+	}
+	_, err = c.WebsocketConn.Write(msg)
 	if err != nil {
 		return err
 	}
@@ -313,6 +466,10 @@ func (c *Context) Close() error {
 	if c.closed {
 		return nil
 	}
+	if c.wsClosed != nil && c.wsClosed.Load() {
+		c.closed = true
+		return nil
+	}
 	err := c.WebsocketConn.Close()
 	if err != nil {
 		return err
@@ -321,15 +478,17 @@ func (c *Context) Close() error {
 	return nil
 }
 
-// SSE Push
+// OnClose register a function called when the websocket or sse connection ends (after the handles return)
+func (c *Context) OnClose(fn func()) {
+	c.closeHooks = append(c.closeHooks, fn)
+}
 
-func (c *Context) Push(msg string) error {
-	_, err := fmt.Fprintf(c.ResponseWriter, msg)
-	if err != nil {
-		return err
+// the hooks are called in reverse order, like defer
+func (c *Context) runCloseHooks() {
+	for i := len(c.closeHooks) - 1; i >= 0; i-- {
+		c.closeHooks[i]()
 	}
-	c.Flusher.Flush()
-	return nil
+	c.closeHooks = nil
 }
 
 // Other request parameters
@@ -368,7 +527,7 @@ func (c *Context) Proto() string {
 
 // Set
 
-func setContext(ctx *Context, router *Router, route string, res http.ResponseWriter, req *http.Request, par httprouter.Params, ws *websocket.Conn, middlewares ...Handle) error {
+func setContext(ctx *Context, router *Router, info *routeInfo, res http.ResponseWriter, req *http.Request, par httprouter.Params, ws *websocket.Conn, middlewares ...Handle) error {
 
 	defer func() {
 		err := recover()
@@ -377,15 +536,32 @@ func setContext(ctx *Context, router *Router, route string, res http.ResponseWri
 		}
 	}()
 
+	// the route handles override the router handles
+	ctx.requestHandle = router.requestHandle
+	ctx.responseHandle = router.responseHandle
+	ctx.errorHandle = router.errorHandle
+	if info.options.RequestHandle != nil {
+		ctx.requestHandle = info.options.RequestHandle
+	}
+	if info.options.ResponseHandle != nil {
+		ctx.responseHandle = info.options.ResponseHandle
+	}
+	if info.options.ErrorHandle != nil {
+		ctx.errorHandle = info.options.ErrorHandle
+	}
+
 	handles := append([]Handle(nil), router.middlewares...)
 	handles = append(handles, middlewares...)
-	ctx.Route = route
+	ctx.Route = info.path
 	ctx.index = 0
 	ctx.handles = handles
 	ctx.Header = nil
 	ctx.Path = nil
 	ctx.Query = nil
 	ctx.Form = nil
+	ctx.HeaderValues = nil
+	ctx.QueryValues = nil
+	ctx.FormValues = nil
 	ctx.Body = nil
 	ctx.Request = req
 	ctx.ResponseWriter = res
@@ -396,28 +572,37 @@ func setContext(ctx *Context, router *Router, route string, res http.ResponseWri
 	ctx.Result = nil
 	ctx.written = false
 	ctx.closed = false
-
-	if strings.Contains(strings.ToLower(req.Header.Get("Content-Type")), "multipart/form-data") ||
-		strings.Contains(strings.ToLower(req.Header.Get("content-type")), "multipart/form-data") {
-		err := req.ParseMultipartForm(router.multipartFormMaxMemory)
-		if err != nil {
-			return err
-		}
-	} else if strings.Contains(strings.ToLower(req.Header.Get("Content-Type")), "application/x-www-form-urlencoded") ||
-		strings.Contains(strings.ToLower(req.Header.Get("content-type")), "application/x-www-form-urlencoded") {
-		err := req.ParseForm()
-		if err != nil {
-			return err
-		}
-	} else {
-		bodyBytes, err := io.ReadAll(req.Body)
-		if err != nil {
-			return err
-		}
-		ctx.Body = bodyBytes
+	ctx.maxBodySize = router.maxBodySize
+	if info.options.MaxBodySize > 0 {
+		ctx.maxBodySize = info.options.MaxBodySize
+	}
+	ctx.formMaxMemory = router.multipartFormMaxMemory
+	ctx.codecs = *router.codecs.Load()
+	ctx.bodyParsed = false
+	ctx.bodyStreamed = false
+	ctx.bodyErr = nil
+	ctx.closeHooks = nil
+	ctx.wsReadTimeout = 0
+	ctx.wsWriteTimeout = 0
+	ctx.wsPushOnly = false
+	ctx.wsClosed = nil
+	if ws != nil {
+		ctx.wsClosed = &atomic.Bool{}
+		wsOptions := router.wsOptions(info)
+		ctx.wsReadTimeout = wsOptions.ReadTimeout
+		ctx.wsWriteTimeout = wsOptions.WriteTimeout
+		ctx.wsPushOnly = wsOptions.PushOnly
+	}
+	if ctx.pushMutex == nil {
+		ctx.pushMutex = &sync.Mutex{}
+	}
+
+	if ctx.maxBodySize > 0 && req.Body != nil {
+		req.Body = &limitedBody{ReadCloser: req.Body, remaining: ctx.maxBodySize}
 	}
 
 	if len(req.Header) > 0 {
+		ctx.HeaderValues = MultiParams(req.Header)
 		ctx.Header = make(map[string]string, len(req.Header))
 		for k, v := range req.Header {
 			if len(v) > 0 {
@@ -433,23 +618,16 @@ func setContext(ctx *Context, router *Router, route string, res http.ResponseWri
 		}
 	}
 
-	if len(req.URL.Query()) > 0 {
-		ctx.Query = make(map[string]string, len(req.URL.Query()))
-		for k, v := range req.URL.Query() {
+	query := req.URL.Query()
+	if len(query) > 0 {
+		ctx.QueryValues = MultiParams(query)
+		ctx.Query = make(map[string]string, len(query))
+		for k, v := range query {
 			if len(v) > 0 {
 				ctx.Query[k] = v[0]
 			}
 		}
 	}
 
-	if len(req.PostForm) > 0 {
-		ctx.Form = make(map[string]string, len(req.PostForm))
-		for k, v := range req.PostForm {
-			if len(v) > 0 {
-				ctx.Form[k] = v[0]
-			}
-		}
-	}
-
 	return nil
 }
@@ -0,0 +1,110 @@
+package easierweb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// request body parsing test
+
+func TestBodyParse(t *testing.T) {
+
+	fmt.Println("\n[TestBodyParse] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/body",
+		MaxBodySize:       16,
+		CloseConsolePrint: true,
+	})
+
+	router.POST("/eager", func(ctx *Context) {
+		ctx.Write(http.StatusOK, ctx.Body)
+	})
+	router.POST("/override", func(ctx *Context) {
+		ctx.Write(http.StatusOK, ctx.Body)
+	}).With(RouteOptions{MaxBodySize: 64})
+	router.POST("/lazy", func(ctx *Context) {
+		if ctx.Body != nil {
+			panic("body parsed before the handle runs")
+		}
+		body, err := ctx.ReadBody()
+		if err != nil {
+			panic(err)
+		}
+		ctx.Write(http.StatusOK, body)
+	}).With(RouteOptions{LazyParse: true})
+	router.POST("/stream", func(ctx *Context) {
+		body, err := io.ReadAll(ctx.BodyReader())
+		if err != nil {
+			ctx.WriteString(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		ctx.Write(http.StatusOK, body)
+	}).With(RouteOptions{LazyParse: true})
+	router.POST("/consumed", func(ctx *Context) {
+		_, _ = io.ReadAll(ctx.BodyReader())
+		var dto routerTestDTO
+		err := ctx.BindJSON(&dto)
+		if !errors.Is(err, ErrBodyConsumed) {
+			panic(fmt.Sprint("the consumed body is bound: ", err))
+		}
+		ctx.NoContent(http.StatusNoContent)
+	}).With(RouteOptions{LazyParse: true})
+	router.POST("/file", func(ctx *Context) {
+		_, err := ctx.GetFile("file")
+		if err != nil {
+			ctx.WriteString(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		ctx.NoContent(http.StatusNoContent)
+	}).With(RouteOptions{LazyParse: true})
+	router.EasyPOST("/easy", func(ctx *Context, dto routerTestDTO) {}).With(RouteOptions{LazyParse: true})
+
+	small := "{\"int\":1}"
+	large := "{\"string\":\"" + strings.Repeat("a", 32) + "\"}"
+
+	cases := []struct {
+		uri  string
+		body string
+		code int
+	}{
+		{"/eager", small, http.StatusOK},
+		{"/eager", large, http.StatusRequestEntityTooLarge},
+		{"/override", large, http.StatusOK},
+		{"/lazy", small, http.StatusOK},
+		{"/lazy", large, http.StatusRequestEntityTooLarge},
+		{"/stream", small, http.StatusOK},
+		{"/stream", large, http.StatusRequestEntityTooLarge},
+		{"/consumed", small, http.StatusNoContent},
+		{"/easy", small, http.StatusNoContent},
+		{"/easy", large, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/test/body"+c.uri, io.NopCloser(strings.NewReader(c.body)))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		fmt.Printf("[TestBodyParse] %s response code: %v, data -> %s \n", c.uri, res.Code, res.Body.String())
+		if res.Code != c.code {
+			t.Fatal("body parse status code error:", c.uri, res.Code)
+		}
+	}
+
+	// the file is read by ParseBody, so the body size limit applies under lazy parsing
+	multipartBody := "--x\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\n\r\n" + strings.Repeat("a", 32) + "\r\n--x--\r\n"
+	req := httptest.NewRequest("POST", "/test/body/file", io.NopCloser(strings.NewReader(multipartBody)))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	fmt.Printf("[TestBodyParse] /file response code: %v, data -> %s \n", res.Code, res.Body.String())
+	if res.Code != http.StatusRequestEntityTooLarge || !strings.Contains(res.Body.String(), ErrBodyTooLarge.Error()) {
+		t.Fatal("file body size limit error:", res.Code)
+	}
+
+	fmt.Println("\n[TestBodyParse] end")
+}
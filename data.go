@@ -1,45 +1,37 @@
 package easierweb
 
-import (
-	"encoding/json"
-	"encoding/xml"
-	"gopkg.in/yaml.v3"
-)
-
 type Data []byte
 
 func (d *Data) ParseJSON(obj any) error {
-	return json.Unmarshal(*d, obj)
+	return d.Parse(JSONCodec, obj)
 }
 
 func (d *Data) ParseYAML(obj any) error {
-	return yaml.Unmarshal(*d, obj)
+	return d.Parse(YAMLCodec, obj)
 }
 
 func (d *Data) ParseXML(obj any) error {
-	return xml.Unmarshal(*d, obj)
+	return d.Parse(XMLCodec, obj)
+}
+
+func (d *Data) Parse(codec Codec, obj any) error {
+	return codec.Unmarshal(*d, obj)
 }
 
 func (d *Data) SaveJSON(obj any) error {
-	marshal, err := json.Marshal(obj)
-	if err != nil {
-		return err
-	}
-	d.Save(marshal)
-	return nil
+	return d.SaveCodec(JSONCodec, obj)
 }
 
 func (d *Data) SaveYAML(obj any) error {
-	marshal, err := yaml.Marshal(obj)
-	if err != nil {
-		return err
-	}
-	d.Save(marshal)
-	return nil
+	return d.SaveCodec(YAMLCodec, obj)
 }
 
 func (d *Data) SaveXML(obj any) error {
-	marshal, err := xml.Marshal(obj)
+	return d.SaveCodec(XMLCodec, obj)
+}
+
+func (d *Data) SaveCodec(codec Codec, obj any) error {
+	marshal, err := codec.Marshal(obj)
 	if err != nil {
 		return err
 	}
@@ -11,30 +11,23 @@ import (
 
 func defaultRequestHandle() RequestHandle {
 	return func(ctx *Context, reqObj any) error {
-		if len(ctx.Form) > 0 {
-			err := ctx.BindForm(reqObj)
-			if err != nil {
-				return err
-			}
-		} else if len(ctx.Body) > 0 {
-			err := ctx.BindJSON(reqObj)
-			if err != nil {
-				return err
-			}
-		}
-		if len(ctx.Query) > 0 {
-			err := ctx.BindQuery(reqObj)
-			if err != nil {
-				return err
-			}
-		}
-		return nil
+		return ctx.Bind(reqObj)
 	}
 }
 
-func defaultResponseHandle() ResponseHandle {
+// problemDetails: render the recognized errors in application/problem+json format
+func defaultResponseHandle(problemDetails bool) ResponseHandle {
 	return func(ctx *Context, result any, err error) {
 		if err != nil {
+			if code, body, ok := ErrorResult(err); ok {
+				LogServerError(ctx, code, err)
+				if problemDetails {
+					ctx.WriteProblem(NewProblem(ctx, err, false))
+					return
+				}
+				ctx.WriteJSON(code, body)
+				return
+			}
 			if result != nil {
 				ctx.WriteJSON(http.StatusBadRequest, result)
 				return
@@ -49,9 +42,24 @@ func defaultResponseHandle() ResponseHandle {
 	}
 }
 
-func defaultErrorHandle() ErrorHandle {
+func defaultErrorHandle(problemDetails bool) ErrorHandle {
 	return func(ctx *Context, err any) {
+		if e, ok := err.(error); ok {
+			if code, body, ok := ErrorResult(e); ok {
+				LogServerError(ctx, code, e)
+				if problemDetails {
+					ctx.WriteProblem(NewProblem(ctx, e, false))
+					return
+				}
+				ctx.WriteJSON(code, body)
+				return
+			}
+		}
 		ctx.Logger.Error(fmt.Sprintf("%s\n%s", err, string(debug.Stack())), slog.String("method", ctx.Request.Method), slog.String("route", ctx.Route))
+		if problemDetails {
+			ctx.WriteProblem(NewProblem(ctx, err, true))
+			return
+		}
 		ctx.WriteString(http.StatusInternalServerError, fmt.Sprintf("{\"msg\":\"%s\"}", err))
 	}
 }
@@ -196,13 +196,18 @@ func DemoSSE(ctx *easierweb.Context) {
 
 	for i := 0; i < 5; i++ {
 
-		// push SSE message, id: {i}, data: hello
-		err := ctx.Push(fmt.Sprintf("data: hello\nid: %v\n\n", i))
+		// push SSE event, id: {i}, event: tick, data: hello
+		err := ctx.PushEvent(easierweb.SSEEvent{ID: fmt.Sprint(i), Event: "tick", Data: "hello"})
 		if err != nil {
 			panic(err)
 		}
 
-		time.Sleep(1 * time.Second)
+		// exit when the client disconnects
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(1 * time.Second):
+		}
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/dpwgc/easierweb/plugins"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 // you can customize error, request, and response handle functions
@@ -21,8 +22,24 @@ func main() {
 		RequestHandle: customRequestHandle(),
 		// customize the auto-writing response data logic
 		ResponseHandle: customResponseHandle(),
+		// enable the request data validation (opt-in), use the built-in tag validator or a custom one
+		Validator: easierweb.TagValidator{},
 		// form request body size limit
 		MultipartFormMaxMemory: 4096,
+		// request body size limit, respond 413 if exceeded
+		MaxBodySize: 10 << 20,
+		// parse the request body on the first binding or ctx.ParseBody(), instead of before the handles run
+		LazyParse: false,
+		// the drain timeout of graceful shutdown (router.Close/router.Shutdown/router.RunWithSignals)
+		ShutdownTimeout: 10 * time.Second,
+		// the timeout of the OnShutdown hooks, they run after the drain
+		ShutdownHookTimeout: 10 * time.Second,
+		// the interval of the sse keepalive comments, negative to disable
+		SSEKeepAlive: 15 * time.Second,
+		// websocket options, the cross-origin websocket connections are rejected by default
+		WS: easierweb.WSOptions{
+			AllowedOrigins: []string{"*"},
+		},
 		// whether to turn off console output
 		CloseConsolePrint: false,
 	})
@@ -0,0 +1,191 @@
+// Package easierwebtest is an in-memory test client for easierweb routers (or any http.Handler), no network port is opened.
+package easierwebtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Client struct {
+	handler http.Handler
+	// in-memory server for websocket and sse connections, it is started on the first use
+	server     *http.Server
+	listener   *pipeListener
+	serverOnce sync.Once
+}
+
+// New create a test client, the handler is usually an *easierweb.Router
+func New(handler http.Handler) *Client {
+	return &Client{
+		handler: handler,
+	}
+}
+
+// Close close the in-memory server and the websocket and sse connections
+func (c *Client) Close() error {
+	if c.server == nil {
+		return nil
+	}
+	err := c.server.Close()
+	_ = c.listener.Close()
+	return err
+}
+
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+func (c *Client) HEAD(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+func (c *Client) OPTIONS(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: http.Header{},
+		query:  url.Values{},
+	}
+}
+
+// Request request builder, errors in building (e.g. json serialization) are reported when the request is sent
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	body    []byte
+	err     error
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+func (r *Request) Cookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// JSON set the json body and the Content-Type header
+func (r *Request) JSON(obj any) *Request {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		r.err = err
+	}
+	r.header.Set("Content-Type", "application/json")
+	return r.Body(body)
+}
+
+// Form set the url-encoded form body and the Content-Type header
+func (r *Request) Form(form url.Values) *Request {
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r.Body([]byte(form.Encode()))
+}
+
+func (r *Request) String(body string) *Request {
+	return r.Body([]byte(body))
+}
+
+func (r *Request) Body(body []byte) *Request {
+	r.body = body
+	return r
+}
+
+// Do send the request to the handler in memory and return the recorded response
+func (r *Request) Do() (*httptest.ResponseRecorder, error) {
+	req, err := r.build("")
+	if err != nil {
+		return nil, err
+	}
+	recorder := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(recorder, req)
+	return recorder, nil
+}
+
+// Expect send the request and return the response assertions, the test fails if the request cannot be sent
+func (r *Request) Expect(t testing.TB) *Response {
+	t.Helper()
+	recorder, err := r.Do()
+	if err != nil {
+		t.Fatalf("%s %s: %s", r.method, r.path, err)
+	}
+	return &Response{
+		t:       t,
+		name:    r.method + " " + r.path,
+		Code:    recorder.Code,
+		Headers: recorder.Header(),
+		Body:    recorder.Body.Bytes(),
+	}
+}
+
+func (r *Request) build(host string) (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	target := r.path
+	if len(r.query) > 0 {
+		if strings.Contains(target, "?") {
+			target += "&" + r.query.Encode()
+		} else {
+			target += "?" + r.query.Encode()
+		}
+	}
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	var req *http.Request
+	if host == "" {
+		req = httptest.NewRequest(r.method, target, body)
+	} else {
+		var err error
+		req, err = http.NewRequest(r.method, host+target, body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	return req, nil
+}
@@ -0,0 +1,140 @@
+package easierwebtest
+
+import (
+	"fmt"
+	"github.com/dpwgc/easierweb"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// in-memory test client test
+
+type clientTestUser struct {
+	ID   int64    `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func clientTestRouter() *easierweb.Router {
+	router := easierweb.New(easierweb.RouterOptions{
+		CloseConsolePrint: true,
+	})
+	router.POST("/user", func(ctx *easierweb.Context) {
+		user := clientTestUser{}
+		err := ctx.BindJSON(&user)
+		if err != nil {
+			panic(err)
+		}
+		user.ID = 1
+		ctx.SetHeader("X-Tenant", ctx.Header.Get("X-Tenant"))
+		ctx.WriteJSON(http.StatusCreated, user)
+	})
+	router.POST("/form", func(ctx *easierweb.Context) {
+		ctx.WriteString(http.StatusOK, ctx.Form.Get("name")+" "+ctx.Query.Get("page"))
+	})
+	router.WS("/ws", func(ctx *easierweb.Context) {
+		for {
+			msg, err := ctx.ReceiveString()
+			if err != nil {
+				return
+			}
+			err = ctx.SendString(ctx.Query.Get("prefix") + msg)
+			if err != nil {
+				return
+			}
+		}
+	})
+	router.SSE("/sse", func(ctx *easierweb.Context) {
+		for i := 1; i <= 2; i++ {
+			err := ctx.Push(fmt.Sprintf(": keepalive\n\nid: %d\nevent: tick\ndata: hello\ndata: world\n\n", i))
+			if err != nil {
+				return
+			}
+		}
+	})
+	return router
+}
+
+func TestClient(t *testing.T) {
+
+	fmt.Println("\n[TestClient] start")
+
+	tc := New(clientTestRouter())
+	defer tc.Close()
+
+	tc.POST("/user").JSON(clientTestUser{Name: "easier", Tags: []string{"a", "b"}}).Header("X-Tenant", "t1").Expect(t).
+		Status(http.StatusCreated).
+		Header("X-Tenant", "t1").
+		JSONPath("$.id", 1).
+		JSONPath("$.name", "easier").
+		JSONPath("$.tags[1]", "b").
+		JSONPath("$.tags", []string{"a", "b"})
+
+	tc.POST("/form").Form(url.Values{"name": {"easier"}}).Query("page", "2").Expect(t).
+		Status(http.StatusOK).
+		String("easier 2")
+
+	tc.GET("/missing").Expect(t).Status(http.StatusNotFound)
+
+	ws, err := tc.GET("/ws").Query("prefix", "echo: ").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"hello", "world"} {
+		err = websocket.Message.Send(ws, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reply string
+		err = websocket.Message.Receive(ws, &reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("[TestClient] websocket reply:", reply)
+		if reply != "echo: "+msg {
+			t.Errorf("unexpected websocket reply: %s", reply)
+		}
+	}
+	_ = ws.Close()
+
+	stream, err := tc.GET("/sse").SSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	for i := 1; i <= 2; i++ {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("[TestClient] sse event:", *event)
+		if event.ID != fmt.Sprint(i) || event.Event != "tick" || event.Data != "hello\nworld" {
+			t.Errorf("unexpected sse event: %+v", *event)
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+
+	fmt.Println("\n[TestJSONPath] start")
+
+	data := map[string]any{"list": []any{map[string]any{"key": "value"}}}
+	cases := []struct {
+		path string
+		ok   bool
+	}{
+		{"$", true},
+		{"$.list[0].key", true},
+		{"$.list[1]", false},
+		{"$.missing", false},
+		{"list", false},
+	}
+	for _, c := range cases {
+		_, err := jsonPathValue(data, c.path)
+		if (err == nil) != c.ok {
+			t.Errorf("json path %s error: %v", c.path, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package easierwebtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response the recorded response, the assertion methods report failures to the test and can be chained
+type Response struct {
+	t       testing.TB
+	name    string
+	Code    int
+	Headers http.Header
+	Body    []byte
+}
+
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Errorf("%s: status code %d, expected %d, body: %s", r.name, r.Code, code, r.Body)
+	}
+	return r
+}
+
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	if actual := r.Headers.Get(key); actual != value {
+		r.t.Errorf("%s: header %s is %q, expected %q", r.name, key, actual, value)
+	}
+	return r
+}
+
+func (r *Response) String(body string) *Response {
+	r.t.Helper()
+	if string(r.Body) != body {
+		r.t.Errorf("%s: body is %q, expected %q", r.name, r.Body, body)
+	}
+	return r
+}
+
+func (r *Response) Contains(s string) *Response {
+	r.t.Helper()
+	if !strings.Contains(string(r.Body), s) {
+		r.t.Errorf("%s: body %q does not contain %q", r.name, r.Body, s)
+	}
+	return r
+}
+
+// JSON parse the json body into obj
+func (r *Response) JSON(obj any) *Response {
+	r.t.Helper()
+	err := json.Unmarshal(r.Body, obj)
+	if err != nil {
+		r.t.Errorf("%s: parse json body error: %s, body: %s", r.name, err, r.Body)
+	}
+	return r
+}
+
+// JSONPath compare the json value at the path with the expected value (compared after json serialization, so 1 equals 1.0)
+// supported syntax: $, $.key, $.list[0], $.list[0].key
+func (r *Response) JSONPath(path string, expected any) *Response {
+	r.t.Helper()
+	var data any
+	err := json.Unmarshal(r.Body, &data)
+	if err != nil {
+		r.t.Errorf("%s: parse json body error: %s, body: %s", r.name, err, r.Body)
+		return r
+	}
+	actual, err := jsonPathValue(data, path)
+	if err != nil {
+		r.t.Errorf("%s: %s, body: %s", r.name, err, r.Body)
+		return r
+	}
+	marshal, err := json.Marshal(expected)
+	if err != nil {
+		r.t.Errorf("%s: serialize expected value error: %s", r.name, err)
+		return r
+	}
+	var want any
+	_ = json.Unmarshal(marshal, &want)
+	if !reflect.DeepEqual(actual, want) {
+		r.t.Errorf("%s: json path %s is %v, expected %v", r.name, path, actual, want)
+	}
+	return r
+}
+
+func jsonPathValue(data any, path string) (any, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %s must start with $", path)
+	}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			object, ok := data.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("json path %s: %s is not in an object", path, key)
+			}
+			data, ok = object[key]
+			if !ok {
+				return nil, fmt.Errorf("json path %s: key %s does not exist", path, key)
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("json path %s: missing ]", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("json path %s: invalid index %s", path, rest[1:end])
+			}
+			rest = rest[end+1:]
+			array, ok := data.([]any)
+			if !ok || index < 0 || index >= len(array) {
+				return nil, fmt.Errorf("json path %s: index %d does not exist", path, index)
+			}
+			data = array[index]
+		default:
+			return nil, fmt.Errorf("json path %s: unexpected %q", path, rest[0])
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,196 @@
+package easierwebtest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"golang.org/x/net/websocket"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// websocket and sse connections are served by an in-memory server, the connections are created by net.Pipe
+
+const testHost = "easierwebtest"
+
+// WS open a websocket connection to the route registered by router.WS, the request headers and query parameters are sent in the handshake
+func (r *Request) WS() (*websocket.Conn, error) {
+	req, err := r.build("ws://" + testHost)
+	if err != nil {
+		return nil, err
+	}
+	config, err := websocket.NewConfig(req.URL.String(), "http://"+testHost+"/")
+	if err != nil {
+		return nil, err
+	}
+	config.Header = req.Header
+	conn, err := r.client.startServer().dial(context.Background(), "", "")
+	if err != nil {
+		return nil, err
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// SSE open a server-sent events stream to the route registered by router.SSE, return an error if the status code is not 200
+func (r *Request) SSE() (*SSEStream, error) {
+	req, err := r.build("http://" + testHost)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: r.client.startServer().dial,
+		},
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("sse status code %d, body: %s", res.StatusCode, body)
+	}
+	return &SSEStream{
+		Response: res,
+		reader:   bufio.NewReader(res.Body),
+	}, nil
+}
+
+type SSEStream struct {
+	Response *http.Response
+	reader   *bufio.Reader
+}
+
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry string
+}
+
+// Next read the next event, it blocks until an event is received or the stream is closed (io.EOF)
+// comment lines (keepalive) are skipped
+func (s *SSEStream) Next() (*SSEEvent, error) {
+	event := &SSEEvent{}
+	var data []string
+	received := false
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if received {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		received = true
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			event.Retry = value
+		}
+	}
+}
+
+func (s *SSEStream) Close() error {
+	return s.Response.Body.Close()
+}
+
+func (c *Client) startServer() *pipeListener {
+	c.serverOnce.Do(func() {
+		c.listener = &pipeListener{
+			conns:  make(chan net.Conn),
+			closed: make(chan struct{}),
+		}
+		c.server = &http.Server{
+			Handler: c.handler,
+		}
+		go func() {
+			_ = c.server.Serve(c.listener)
+		}()
+	})
+	return c.listener
+}
+
+// pipeListener an in-memory net.Listener, each dial creates a net.Pipe
+type pipeListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+	// the server side of the dialed connections, hijacked websocket connections are not closed by the http server
+	dialed []net.Conn
+	mutex  sync.Mutex
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		for _, conn := range l.dialed {
+			_ = conn.Close()
+		}
+	})
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+func (l *pipeListener) dial(ctx context.Context, _, _ string) (net.Conn, error) {
+	server, client := net.Pipe()
+	select {
+	case l.conns <- server:
+		l.mutex.Lock()
+		l.dialed = append(l.dialed, server)
+		l.mutex.Unlock()
+		return client, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (pipeAddr) String() string {
+	return testHost
+}
@@ -0,0 +1,52 @@
+package easierweb
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// the handles of unmatched requests, they run through the global middlewares like the registered routes
+// the Allow header is set before the MethodNotAllowedHandle and OptionsHandle are called
+// each router has its own fallback routes, so the routers do not share the route state
+
+func (r *Router) setFallbackHandles() {
+	r.router.NotFound = r.fallback(&routeInfo{options: RouteOptions{LazyParse: true}}, &r.notFoundHandle)
+	r.router.MethodNotAllowed = r.fallback(&routeInfo{options: RouteOptions{LazyParse: true}}, &r.methodNotAllowedHandle)
+	r.router.GlobalOPTIONS = r.fallback(&routeInfo{method: MethodOPTIONS, options: RouteOptions{LazyParse: true}}, &r.optionsHandle)
+}
+
+func (r *Router) fallback(info *routeInfo, handle *Handle) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		r.handle(info, *handle, res, req, httprouter.Params{}, nil, false)
+	})
+}
+
+// NotFound set the handle of requests that do not match any route
+func (r *Router) NotFound(handle Handle) *Router {
+	r.notFoundHandle = handle
+	return r
+}
+
+// MethodNotAllowed set the handle of requests whose path matches but the method does not
+func (r *Router) MethodNotAllowed(handle Handle) *Router {
+	r.methodNotAllowedHandle = handle
+	return r
+}
+
+// GlobalOPTIONS set the handle of OPTIONS requests that do not match any OPTIONS route (e.g. CORS preflight requests)
+func (r *Router) GlobalOPTIONS(handle Handle) *Router {
+	r.optionsHandle = handle
+	return r
+}
+
+func defaultNotFoundHandle(ctx *Context) {
+	ctx.WriteString(http.StatusNotFound, "404 page not found")
+}
+
+func defaultMethodNotAllowedHandle(ctx *Context) {
+	ctx.WriteString(http.StatusMethodNotAllowed, "405 method not allowed")
+}
+
+func defaultOptionsHandle(ctx *Context) {
+	ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,66 @@
+package easierweb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// not found, method not allowed and OPTIONS handles test
+
+func TestFallbackHandles(t *testing.T) {
+
+	fmt.Println("\n[TestFallbackHandles] start")
+
+	router := New(RouterOptions{
+		RootPath: "/test/fallback",
+		NotFoundHandle: func(ctx *Context) {
+			ctx.WriteJSON(http.StatusNotFound, map[string]string{"msg": "not found: " + ctx.URL().Path})
+		},
+		CloseConsolePrint: true,
+	}).Use(func(ctx *Context) {
+		ctx.SetHeader("X-Middleware", "true")
+		ctx.Next()
+	})
+	router.GET("/user", func(ctx *Context) {
+		ctx.WriteString(http.StatusOK, "user")
+	})
+	router.POST("/user", func(ctx *Context) {
+		ctx.WriteString(http.StatusOK, "user")
+	})
+
+	cases := []struct {
+		method string
+		uri    string
+		code   int
+		allow  string
+		body   string
+	}{
+		{"GET", "/test/fallback/missing", http.StatusNotFound, "", "not found: /test/fallback/missing"},
+		{"DELETE", "/test/fallback/user", http.StatusMethodNotAllowed, "GET, OPTIONS, POST", "405 method not allowed"},
+		{"OPTIONS", "/test/fallback/user", http.StatusNoContent, "GET, OPTIONS, POST", ""},
+	}
+	for _, c := range cases {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(c.method, c.uri, nil))
+		fmt.Println("[TestFallbackHandles]", c.method, c.uri, "->", res.Code, res.Header().Get("Allow"), res.Body.String())
+		if res.Code != c.code || res.Header().Get("Allow") != c.allow || !strings.Contains(res.Body.String(), c.body) {
+			t.Errorf("%s %s: unexpected response %d %s %s", c.method, c.uri, res.Code, res.Header().Get("Allow"), res.Body.String())
+		}
+		if res.Header().Get("X-Middleware") != "true" {
+			t.Errorf("%s %s: middlewares are not executed", c.method, c.uri)
+		}
+	}
+
+	// custom handles can be set after creating the router
+	router.MethodNotAllowed(func(ctx *Context) {
+		ctx.WriteString(http.StatusMethodNotAllowed, "allow: "+ctx.ResponseWriter.Header().Get("Allow"))
+	})
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest("PUT", "/test/fallback/user", nil))
+	if res.Body.String() != "allow: GET, OPTIONS, POST" {
+		t.Errorf("unexpected method not allowed response: %s", res.Body.String())
+	}
+}
@@ -6,148 +6,219 @@ import (
 
 type Group struct {
 	router      *Router
+	parent      *Group
 	path        string
 	middlewares []Handle
+	options     RouteOptions
 }
 
 func (r *Router) Group(path string, middlewares ...Handle) *Group {
 	return &Group{
 		router:      r,
 		path:        path,
-		middlewares: middlewares,
+		middlewares: append([]Handle(nil), middlewares...),
 	}
 }
 
+// Group create a nested group, the path and middlewares are appended to the parent group
+func (g *Group) Group(path string, middlewares ...Handle) *Group {
+	return &Group{
+		router:      g.router,
+		parent:      g,
+		path:        g.path + path,
+		middlewares: append([]Handle(nil), middlewares...),
+	}
+}
+
+// Use add middlewares to the group, they apply to the routes registered afterwards (including nested groups)
+func (g *Group) Use(middlewares ...Handle) *Group {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Options set the default route options of the group, they apply to the routes registered afterwards (including nested groups)
+// the options of nested groups and router.With/group.With take precedence
+func (g *Group) Options(opts RouteOptions) *Group {
+	g.options = opts
+	return g
+}
+
+// compose the middlewares of the parent groups, the group and the route into a new slice
+func (g *Group) handles(middlewares []Handle) []Handle {
+	var groups []*Group
+	for p := g; p != nil; p = p.parent {
+		groups = append(groups, p)
+	}
+	var handles []Handle
+	for i := len(groups) - 1; i >= 0; i-- {
+		handles = append(handles, groups[i].middlewares...)
+	}
+	return append(handles, middlewares...)
+}
+
+// apply the group options to the last registered routes
+func (g *Group) applyOptions() {
+	if g.parent != nil {
+		g.parent.applyOptions()
+	}
+	g.router.With(g.options)
+}
+
 // easier usage function
 
 func (g *Group) EasyGET(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyGET(g.path+path, easyHandle, middlewares...)
+	g.router.EasyGET(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyHEAD(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyHEAD(g.path+path, easyHandle, middlewares...)
+	g.router.EasyHEAD(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyOPTIONS(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyOPTIONS(g.path+path, easyHandle, middlewares...)
+	g.router.EasyOPTIONS(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyPOST(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyPOST(g.path+path, easyHandle, middlewares...)
+	g.router.EasyPOST(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyPUT(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyPUT(g.path+path, easyHandle, middlewares...)
+	g.router.EasyPUT(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyPATCH(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyPATCH(g.path+path, easyHandle, middlewares...)
+	g.router.EasyPATCH(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyDELETE(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyDELETE(g.path+path, easyHandle, middlewares...)
+	g.router.EasyDELETE(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyAny(path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyAny(g.path+path, easyHandle, middlewares...)
+	g.router.EasyAny(g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) EasyAPI(method, path string, easyHandle any, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.EasyAPI(method, g.path+path, easyHandle, middlewares...)
+	g.router.EasyAPI(method, g.path+path, easyHandle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
+func (g *Group) planAPI(method, path string, plan *easyPlan, middlewares ...Handle) {
+	g.router.planAPI(method, g.path+path, plan, g.handles(middlewares)...)
+	g.applyOptions()
+}
+
 // basic usage function
 
 func (g *Group) GET(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.GET(g.path+path, handle, middlewares...)
+	g.router.GET(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) HEAD(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.HEAD(g.path+path, handle, middlewares...)
+	g.router.HEAD(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) OPTIONS(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.OPTIONS(g.path+path, handle, middlewares...)
+	g.router.OPTIONS(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) POST(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.POST(g.path+path, handle, middlewares...)
+	g.router.POST(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) PUT(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.PUT(g.path+path, handle, middlewares...)
+	g.router.PUT(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) PATCH(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.PATCH(g.path+path, handle, middlewares...)
+	g.router.PATCH(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) DELETE(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.DELETE(g.path+path, handle, middlewares...)
+	g.router.DELETE(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) Any(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.Any(g.path+path, handle, middlewares...)
+	g.router.Any(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) API(method, path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.API(method, g.path+path, handle, middlewares...)
+	g.router.API(method, g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) WS(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.WS(g.path+path, handle, middlewares...)
+	g.router.WS(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) SSE(path string, handle Handle, middlewares ...Handle) *Group {
-	middlewares = append(g.middlewares, middlewares...)
-	g.router.SSE(g.path+path, handle, middlewares...)
+	g.router.SSE(g.path+path, handle, g.handles(middlewares)...)
+	g.applyOptions()
+	return g
+}
+
+func (g *Group) Mount(prefix string, handler http.Handler, middlewares ...Handle) *Group {
+	g.router.Mount(g.path+prefix, handler, g.handles(middlewares)...)
+	g.applyOptions()
+	return g
+}
+
+// With set the options of the last registered route
+func (g *Group) With(opts RouteOptions) *Group {
+	g.router.With(opts)
+	return g
+}
+
+// Name name the last registered route
+func (g *Group) Name(name string) *Group {
+	g.router.Name(name)
 	return g
 }
 
 func (g *Group) Static(path, dir string) *Group {
 	g.router.Static(g.path+path, dir)
+	g.applyOptions()
 	return g
 }
 
 func (g *Group) StaticFS(path string, fs http.FileSystem) *Group {
 	g.router.StaticFS(g.path+path, fs)
+	g.applyOptions()
 	return g
 }
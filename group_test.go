@@ -3,8 +3,9 @@ package easierweb
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
-	"time"
 )
 
 // group test
@@ -30,30 +31,70 @@ func TestGroup(t *testing.T) {
 		group3.GET("/hello", groupTeatApi)
 	}
 
-	go func() {
-		time.Sleep(3 * time.Second)
-		groupTeatHttpClient("GET", "/1/hello", "")
-		time.Sleep(1 * time.Second)
-		groupTeatHttpClient("GET", "/2/hello", "")
-		time.Sleep(1 * time.Second)
-		groupTeatHttpClient("GET", "/3/hello", "")
-		time.Sleep(1 * time.Second)
-		fmt.Println("\n[TestGroup](go func) close router")
-		err := router.Close()
-		if err != nil {
-			panic(err)
+	for _, uri := range []string{"/1/hello", "/2/hello", "/3/hello"} {
+		code := groupTeatHttpClient(router, "GET", uri, "")
+		if code != http.StatusOK {
+			t.Errorf("GET %s status code %d, expected 200", uri, code)
 		}
-	}()
-
-	// started on port 80
-	err := router.Run(":80")
-	if err != nil && err.Error() != "http: Server closed" {
-		panic(err)
 	}
 
 	fmt.Println("\n[TestGroup] end")
 }
 
+// nested group test
+func TestNestedGroup(t *testing.T) {
+
+	fmt.Println("\n[TestNestedGroup] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/nested",
+		CloseConsolePrint: true,
+	})
+
+	trace := func(name string) Handle {
+		return func(ctx *Context) {
+			ctx.SetHeader("X-Trace", ctx.ResponseWriter.Header().Get("X-Trace")+name+",")
+			ctx.Next()
+		}
+	}
+
+	// the backing array has spare capacity, the routes must not overwrite each other's middlewares
+	shared := make([]Handle, 0, 8)
+	shared = append(shared, trace("api"))
+	api := router.Group("/api", shared...).Options(RouteOptions{MaxBodySize: 8})
+	v1 := api.Group("/v1", trace("v1"))
+	v1.Use(trace("v1-use"))
+	api.Use(trace("api-use"))
+
+	v1.POST("/a", groupTeatApi, trace("a"))
+	v1.POST("/b", groupTeatApi, trace("b"))
+	v1.POST("/c", groupTeatApi).With(RouteOptions{MaxBodySize: 64})
+
+	cases := []struct {
+		uri   string
+		body  string
+		code  int
+		trace string
+	}{
+		{"/api/v1/a", "", http.StatusOK, "api,api-use,v1,v1-use,a,"},
+		{"/api/v1/b", "", http.StatusOK, "api,api-use,v1,v1-use,b,"},
+		// the group options are inherited by nested groups, and the route options take precedence
+		{"/api/v1/b", "0123456789", http.StatusRequestEntityTooLarge, ""},
+		{"/api/v1/c", "0123456789", http.StatusOK, ""},
+	}
+	for _, c := range cases {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest("POST", "/test/nested"+c.uri, strings.NewReader(c.body)))
+		fmt.Println("[TestNestedGroup]", c.uri, "->", res.Code, res.Header().Get("X-Trace"))
+		if res.Code != c.code {
+			t.Errorf("%s status code %d, expected %d", c.uri, res.Code, c.code)
+		}
+		if c.trace != "" && res.Header().Get("X-Trace") != c.trace {
+			t.Errorf("%s middlewares %s, expected %s", c.uri, res.Header().Get("X-Trace"), c.trace)
+		}
+	}
+}
+
 // middleware
 func groupTestMiddleware(ctx *Context) {
 	fmt.Println("[TestGroup](groupTestMiddleware) start")
@@ -84,11 +125,9 @@ func groupTeatApi(ctx *Context) {
 	ctx.WriteString(http.StatusOK, "hello")
 }
 
-func groupTeatHttpClient(method, uri, body string) {
+func groupTeatHttpClient(router *Router, method, uri, body string) int {
 	fmt.Printf("\n[TestGroup](groupTeatHttpClient) request method: %s, uri: %s, body: %s \n", method, uri, body)
-	code, result, err := requestDo(method, "http://localhost/test/group"+uri, []byte(body))
-	if err != nil {
-		panic(err)
-	}
+	code, result := requestDo(router, method, "/test/group"+uri, []byte(body))
 	fmt.Printf("[TestGroup](groupTeatHttpClient) response code: %v, data -> %s \n", code, string(result))
+	return code
 }
@@ -2,10 +2,12 @@ package easierweb
 
 import (
 	"errors"
+	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"golang.org/x/net/websocket"
 	"net/http"
 	"reflect"
+	"runtime"
 )
 
 type Handle func(ctx *Context)
@@ -16,26 +18,44 @@ type ResponseHandle func(ctx *Context, result any, err error)
 
 type ErrorHandle func(ctx *Context, err any)
 
-func (r *Router) handle(route string, handle Handle, res http.ResponseWriter, req *http.Request, par httprouter.Params, ws *websocket.Conn, sse bool, middlewares ...Handle) {
+func (r *Router) handle(info *routeInfo, handle Handle, res http.ResponseWriter, req *http.Request, par httprouter.Params, ws *websocket.Conn, sse bool, middlewares ...Handle) {
 
 	ctx := r.contextPool.Get().(*Context)
 
-	err := setContext(ctx, r, route, res, req, par, ws, middlewares...)
+	err := setContext(ctx, r, info, res, req, par, ws, middlewares...)
 
 	defer func() {
-		if ctx != nil {
-			r.contextPool.Put(ctx)
-		}
 		sErr := recover()
-		if sErr != nil && r.errorHandle != nil {
+		if sErr != nil {
 			r.errorBottomUp(ctx, sErr)
 		}
+		// the context is put back after the error handle, it reads the route handles of the context
+		r.contextPool.Put(ctx)
 	}()
 
 	if err != nil {
 		panic(err)
 	}
 
+	// parse the request body before the handles run, unless the lazy parsing is enabled
+	if !r.lazyParse && !info.options.LazyParse {
+		err = ctx.ParseBody()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// websocket and sse connections are notified and drained on shutdown
+	if ws != nil || sse {
+		defer r.trackConn(ctx)()
+		defer ctx.runCloseHooks()
+	}
+
+	heartbeat := heartbeatFrom(req)
+	if ws != nil && heartbeat != nil {
+		defer heartbeat.start(ctx)()
+	}
+
 	if sse {
 		res.Header().Set("Content-Type", "text/event-stream")
 		res.Header().Set("Cache-Control", "no-cache")
@@ -46,6 +66,13 @@ func (r *Router) handle(route string, handle Handle, res http.ResponseWriter, re
 			panic(errors.New("client does not support server-sent events"))
 		}
 		ctx.Flusher = flusher
+		keepAlive := r.sseKeepAlive
+		if info.options.SSEKeepAlive != 0 {
+			keepAlive = info.options.SSEKeepAlive
+		}
+		if keepAlive > 0 {
+			defer ctx.keepAlive(keepAlive)()
+		}
 	}
 
 	// middleware execution
@@ -58,96 +85,186 @@ func (r *Router) handle(route string, handle Handle, res http.ResponseWriter, re
 	// if a websocket connection exists, the websocket connection is automatically closed when the function returns
 	if ws != nil {
 		err = ctx.Close()
-		if err != nil {
+		// the connection may have been closed by the shutdown or the heartbeat
+		if err != nil && !r.isShuttingDown() && !heartbeat.isDead() {
 			panic(err)
 		}
 	}
 }
 
-func (r *Router) easyHandle(easyHandle any) Handle {
+// easyPlan the signature analysis result of an easy handle, it is created once at registration time
+type easyPlan struct {
+	fn       reflect.Value
+	funcName string
+	// the type of the second parameter, nil if there is no request object
+	reqType reflect.Type
+	// the type of the first return value (if it is not an error), nil if there is no result return
+	resType reflect.Type
+	// the index of the error return value, -1 if there is no error return
+	errIndex int
+	// fast path for the handles without a request object, called without reflection
+	// the handles with a request object are called by reflect.Call, only the typed handles (invoke) avoid it
+	fast func(ctx *Context) error
+	// generic typed handles bind and respond by themselves
+	invoke func(r *Router, ctx *Context)
+}
+
+var (
+	contextPtrType = reflect.TypeOf((*Context)(nil))
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// newEasyPlan verify the signature of the easy handle and build the invocation plan, panic if the signature does not match
+func newEasyPlan(easyHandle any) *easyPlan {
+	switch fn := easyHandle.(type) {
+	case func(ctx *Context):
+		return &easyPlan{
+			fn:       reflect.ValueOf(fn),
+			funcName: funcName(fn),
+			errIndex: -1,
+			fast: func(ctx *Context) error {
+				fn(ctx)
+				return nil
+			},
+		}
+	case func(ctx *Context) error:
+		return &easyPlan{
+			fn:       reflect.ValueOf(fn),
+			funcName: funcName(fn),
+			fast:     fn,
+		}
+	}
+
+	funcType := reflect.TypeOf(easyHandle)
+	if funcType == nil || funcType.Kind() != reflect.Func {
+		panic(errors.New("handle is not a function"))
+	}
+	if funcType.IsVariadic() || funcType.NumIn() < 1 || funcType.NumIn() > 2 || funcType.In(0) != contextPtrType {
+		panic(fmt.Errorf("handle input parameters does not match: %s", funcType))
+	}
+	if funcType.NumOut() > 2 || (funcType.NumOut() == 2 && funcType.Out(1) != errorType) {
+		panic(fmt.Errorf("handle return values does not match: %s", funcType))
+	}
+
+	plan := &easyPlan{
+		fn:       reflect.ValueOf(easyHandle),
+		funcName: funcName(easyHandle),
+		errIndex: -1,
+	}
+	if funcType.NumIn() == 2 {
+		plan.reqType = funcType.In(1)
+	}
+	if funcType.NumOut() == 2 {
+		plan.resType = funcType.Out(0)
+		plan.errIndex = 1
+	} else if funcType.NumOut() == 1 {
+		if funcType.Out(0) == errorType {
+			plan.errIndex = 0
+		} else {
+			plan.resType = funcType.Out(0)
+		}
+	}
+	return plan
+}
+
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	return f.Name()
+}
+
+func (r *Router) easyHandle(plan *easyPlan) Handle {
 	return func(ctx *Context) {
 		// verify
-		if r.requestHandle == nil {
+		if ctx.requestHandle == nil {
 			panic(errors.New("request handle is empty"))
 		}
-		if r.responseHandle == nil {
+		if ctx.responseHandle == nil {
 			panic(errors.New("response handle is empty"))
 		}
-		// reflection gets the type of function
-		funcType := reflect.TypeOf(easyHandle)
+
+		if plan.invoke != nil {
+			plan.invoke(r, ctx)
+			return
+		}
+
+		// no request object, call the function directly
+		if plan.fast != nil {
+			ctx.responseHandle(ctx, nil, plan.fast(ctx))
+			return
+		}
 
 		// create a slice of the parameter value
 		var paramValues []reflect.Value
 
-		var reqObj any = nil
 		// if there is no second parameter, there is no auto-binding
-		if funcType.NumIn() == 1 {
-			paramValues = make([]reflect.Value, 1)
-			paramValues[0] = reflect.ValueOf(ctx).Elem().Addr()
-		} else if funcType.NumIn() == 2 {
-			paramValues = make([]reflect.Value, 2)
-			paramValues[0] = reflect.ValueOf(ctx).Elem().Addr()
-			paramValues[1] = reflect.New(funcType.In(1)).Elem()
-			reqObj = paramValues[1].Addr().Interface()
+		if plan.reqType == nil {
+			paramValues = []reflect.Value{reflect.ValueOf(ctx)}
 		} else {
-			panic(errors.New("handle input parameters does not match"))
-		}
-
-		if reqObj != nil {
-			err := r.requestHandle(ctx, reqObj)
+			reqValue := reflect.New(plan.reqType)
+			reqObj := reqValue.Interface()
+			// binding or validation failed, the handle is not executed
+			err := r.bindRequest(ctx, reqObj)
 			if err != nil {
-				r.responseHandle(ctx, nil, err)
+				ctx.responseHandle(ctx, nil, err)
+				return
 			}
+			paramValues = []reflect.Value{reflect.ValueOf(ctx), reqValue.Elem()}
 		}
 
 		// call the function
-		returnValues := reflect.ValueOf(easyHandle).Call(paramValues)
+		returnValues := plan.fn.Call(paramValues)
 
-		// no object return, no error return
-		if len(returnValues) == 0 {
-			r.responseHandle(ctx, nil, nil)
-			return
-		}
-
-		if len(returnValues) > 2 {
-			panic(errors.New("handle return values does not match"))
-		}
-
-		// if just one value return
-		if len(returnValues) == 1 {
-			firstValue, isErr := returnValues[0].Interface().(error)
-			// if first return value is error
-			if isErr {
-				// return error
-				r.responseHandle(ctx, nil, firstValue)
-				return
-			}
+		// get the error value
+		var errValue error = nil
+		if plan.errIndex >= 0 {
+			errValue, _ = returnValues[plan.errIndex].Interface().(error)
 		}
 
 		// get the result value
 		var resultValue any = nil
-
-		if returnValues[0].IsValid() && returnValues[0].Kind() == reflect.Ptr && returnValues[0].Elem().IsValid() {
-			resultValue = returnValues[0].Elem().Interface()
-		} else if returnValues[0].IsValid() && returnValues[0].Kind() == reflect.Slice {
-			resultValue = returnValues[0].Interface()
+		if plan.resType != nil {
+			value := returnValues[0]
+			if value.Kind() == reflect.Ptr && !value.IsNil() {
+				resultValue = value.Elem().Interface()
+			} else if value.Kind() == reflect.Slice {
+				resultValue = value.Interface()
+			}
 		}
 
-		// just result return
-		if len(returnValues) == 1 {
-			r.responseHandle(ctx, resultValue, nil)
-			return
-		}
+		ctx.responseHandle(ctx, resultValue, errValue)
+	}
+}
 
-		// has result return and error return
-		errValue, _ := returnValues[1].Interface().(error)
-		r.responseHandle(ctx, resultValue, errValue)
+// bind the request object and validate it
+// the default values are set before the request handle, so every request handle (including the plugins) keeps the explicit zero values
+func (r *Router) bindRequest(ctx *Context, reqObj any) error {
+	err := setDefaults(reqObj)
+	if err != nil {
+		return err
+	}
+	err = ctx.requestHandle(ctx, reqObj)
+	if err != nil {
+		return err
 	}
+	if r.validator != nil {
+		return r.validator.Validate(reqObj)
+	}
+	return nil
 }
 
 func (r *Router) errorBottomUp(ctx *Context, err any) {
 	defer func() {
 		_ = recover()
 	}()
-	r.errorHandle(ctx, err)
+	// the route error handle, or the router error handle if the context is not set
+	errorHandle := r.errorHandle
+	if ctx.errorHandle != nil {
+		errorHandle = ctx.errorHandle
+	}
+	if errorHandle != nil {
+		errorHandle(ctx, err)
+	}
 }
@@ -0,0 +1,196 @@
+package easierweb
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// easy handle plan test
+
+func TestEasyPlan(t *testing.T) {
+
+	fmt.Println("\n[TestEasyPlan] start")
+
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+	})
+
+	valid := []any{
+		func(ctx *Context) {},
+		func(ctx *Context) error { return nil },
+		routerTestEasyQueryAPI,
+		routerTestEasySaveAPI,
+		routerTestEasyDelAPI,
+		routerTestErrorReturnAPI,
+	}
+	for i, v := range valid {
+		router.EasyGET(fmt.Sprintf("/valid/%v", i), v)
+	}
+
+	invalid := []any{
+		"not a function",
+		func() {},
+		func(ctx Context) {},
+		func(ctx *Context, a, b routerTestDTO) {},
+		func(ctx *Context) (*routerTestDTO, error, error) { return nil, nil, nil },
+		func(ctx *Context) (*routerTestDTO, *routerTestDTO) { return nil, nil },
+	}
+	for i, v := range invalid {
+		func() {
+			defer func() {
+				err := recover()
+				if err == nil {
+					t.Fatal("invalid handle registered:", reflect.TypeOf(v))
+				}
+				fmt.Println("[TestEasyPlan] register panic ->", err)
+			}()
+			router.EasyGET(fmt.Sprintf("/invalid/%v", i), v)
+		}()
+	}
+
+	fmt.Println("\n[TestEasyPlan] end")
+}
+
+// benchmark of the per-request overhead, compared with the old implementation that analyzes the signature on every request
+// the handles with a request object are called by reflect.Call in both, so Legacy and Plan are about the same
+// only the handles without a request object (NoRequest) and the typed handles skip reflection
+
+func BenchmarkEasyHandleLegacy(b *testing.B) {
+	router, ctx := easyHandleBenchmarkSetup()
+	handle := legacyEasyHandle(router, easyHandleBenchmarkAPI)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handle(ctx)
+	}
+}
+
+func BenchmarkEasyHandlePlan(b *testing.B) {
+	router, ctx := easyHandleBenchmarkSetup()
+	handle := router.easyHandle(newEasyPlan(easyHandleBenchmarkAPI))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handle(ctx)
+	}
+}
+
+// the typed handles are called without reflection
+func BenchmarkTypedHandle(b *testing.B) {
+	router, ctx := easyHandleBenchmarkSetup()
+	handle := router.easyHandle(newTypedPlan(easyHandleBenchmarkAPI))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handle(ctx)
+	}
+}
+
+func BenchmarkEasyHandleLegacyNoRequest(b *testing.B) {
+	router, ctx := easyHandleBenchmarkSetup()
+	handle := legacyEasyHandle(router, routerTestErrorReturnAPI)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handle(ctx)
+	}
+}
+
+func BenchmarkEasyHandlePlanNoRequest(b *testing.B) {
+	router, ctx := easyHandleBenchmarkSetup()
+	handle := router.easyHandle(newEasyPlan(routerTestErrorReturnAPI))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handle(ctx)
+	}
+}
+
+func easyHandleBenchmarkSetup() (*Router, *Context) {
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+		// no binding and no writing, only measure the invocation overhead
+		RequestHandle: func(ctx *Context, reqObj any) error {
+			reqObj.(*routerTestDTO).Int64 = 1
+			return nil
+		},
+		ResponseHandle: func(ctx *Context, result any, err error) {},
+		Validator:      benchmarkValidator{},
+	})
+	ctx := &Context{
+		Request:        httptest.NewRequest("POST", "/", nil),
+		ResponseWriter: httptest.NewRecorder(),
+		// the handles are resolved by setContext in the request pipeline
+		requestHandle:  router.requestHandle,
+		responseHandle: router.responseHandle,
+		errorHandle:    router.errorHandle,
+	}
+	return router, ctx
+}
+
+func easyHandleBenchmarkAPI(ctx *Context, dto routerTestDTO) (*routerTestDTO, error) {
+	return &dto, nil
+}
+
+type benchmarkValidator struct{}
+
+func (v benchmarkValidator) Validate(obj any) error {
+	return nil
+}
+
+// the easy handle implementation before the invocation plan was introduced
+func legacyEasyHandle(r *Router, easyHandle any) Handle {
+	return func(ctx *Context) {
+		funcType := reflect.TypeOf(easyHandle)
+		var paramValues []reflect.Value
+		var reqObj any = nil
+		if funcType.NumIn() == 1 {
+			paramValues = make([]reflect.Value, 1)
+			paramValues[0] = reflect.ValueOf(ctx).Elem().Addr()
+		} else if funcType.NumIn() == 2 {
+			paramValues = make([]reflect.Value, 2)
+			paramValues[0] = reflect.ValueOf(ctx).Elem().Addr()
+			paramValues[1] = reflect.New(funcType.In(1)).Elem()
+			reqObj = paramValues[1].Addr().Interface()
+		} else {
+			panic(errors.New("handle input parameters does not match"))
+		}
+		if reqObj != nil {
+			err := r.requestHandle(ctx, reqObj)
+			if err != nil {
+				r.responseHandle(ctx, nil, err)
+				return
+			}
+		}
+		returnValues := reflect.ValueOf(easyHandle).Call(paramValues)
+		if len(returnValues) == 0 {
+			r.responseHandle(ctx, nil, nil)
+			return
+		}
+		if len(returnValues) > 2 {
+			panic(errors.New("handle return values does not match"))
+		}
+		if len(returnValues) == 1 {
+			firstValue, isErr := returnValues[0].Interface().(error)
+			if isErr {
+				r.responseHandle(ctx, nil, firstValue)
+				return
+			}
+		}
+		var resultValue any = nil
+		if returnValues[0].IsValid() && returnValues[0].Kind() == reflect.Ptr && returnValues[0].Elem().IsValid() {
+			resultValue = returnValues[0].Elem().Interface()
+		} else if returnValues[0].IsValid() && returnValues[0].Kind() == reflect.Slice {
+			resultValue = returnValues[0].Interface()
+		}
+		if len(returnValues) == 1 {
+			r.responseHandle(ctx, resultValue, nil)
+			return
+		}
+		errValue, _ := returnValues[1].Interface().(error)
+		r.responseHandle(ctx, resultValue, errValue)
+	}
+}
@@ -0,0 +1,194 @@
+package easierweb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/websocket"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// websocket heartbeats
+// golang.org/x/net/websocket answers the pings and discards the pongs internally, so the pongs are tracked as read activity:
+// the reads of the hijacked connection (messages, pongs and pings of the client) keep the connection alive
+// nothing is read while the handle is not receiving, so the handles that only send should use WSOptions.PushOnly
+
+var pingCodec = websocket.Codec{
+	Marshal: func(v any) ([]byte, byte, error) {
+		msg, _ := v.([]byte)
+		return msg, websocket.PingFrame, nil
+	},
+}
+
+// Ping send a ping frame, the client answers it with a pong frame
+func (c *Context) Ping() error {
+	err := c.setWriteDeadline()
+	if err != nil {
+		return err
+	}
+	return pingCodec.Send(c.WebsocketConn, []byte(nil))
+}
+
+// ReceiveWithTimeout receive a message, return an i/o timeout error if no message is received within the timeout
+// the connection should be closed if the timeout happens while a message is being received
+func (c *Context) ReceiveWithTimeout(timeout time.Duration) ([]byte, error) {
+	if c.wsPushOnly {
+		return nil, ErrWSPushOnly
+	}
+	err := c.WebsocketConn.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = c.WebsocketConn.SetReadDeadline(time.Time{})
+	}()
+	var buf []byte
+	err = websocket.Message.Receive(c.WebsocketConn, &buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// IsTimeout check whether the error is a timeout error, e.g. the error of ReceiveWithTimeout
+func IsTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+type wsHeartbeatKey struct{}
+
+// wsHeartbeat the heartbeat state of a websocket connection
+type wsHeartbeat struct {
+	opts WSOptions
+	// unix nano of the last read
+	lastRead atomic.Int64
+	pinging  atomic.Bool
+	dead     atomic.Bool
+	conn     net.Conn
+}
+
+// newHeartbeat nil if the heartbeat is disabled
+func newHeartbeat(opts WSOptions) *wsHeartbeat {
+	if opts.PingInterval <= 0 && opts.IdleTimeout <= 0 {
+		return nil
+	}
+	h := &wsHeartbeat{opts: opts}
+	h.lastRead.Store(time.Now().UnixNano())
+	return h
+}
+
+func heartbeatFrom(req *http.Request) *wsHeartbeat {
+	h, _ := req.Context().Value(wsHeartbeatKey{}).(*wsHeartbeat)
+	return h
+}
+
+// withHeartbeat track the reads of the hijacked connection
+func (h *wsHeartbeat) withHeartbeat(res http.ResponseWriter, req *http.Request) (http.ResponseWriter, *http.Request) {
+	if h == nil {
+		return res, req
+	}
+	return &heartbeatResponseWriter{ResponseWriter: res, heartbeat: h}, req.WithContext(context.WithValue(req.Context(), wsHeartbeatKey{}, h))
+}
+
+func (h *wsHeartbeat) isDead() bool {
+	return h != nil && h.dead.Load()
+}
+
+// start ping the client and check the idle timeout until the returned stop function is called
+func (h *wsHeartbeat) start(ctx *Context) (stop func()) {
+	interval := h.opts.PingInterval
+	if interval <= 0 {
+		interval = h.opts.IdleTimeout / 2
+	}
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				idle := time.Since(time.Unix(0, h.lastRead.Load()))
+				if h.opts.IdleTimeout > 0 && idle > h.opts.IdleTimeout {
+					h.kill(ctx)
+					return
+				}
+				// the ping is sent by another goroutine, a blocked write does not block the idle check
+				if h.opts.PingInterval > 0 && h.pinging.CompareAndSwap(false, true) {
+					go func(ws *websocket.Conn) {
+						defer h.pinging.Store(false)
+						_ = pingCodec.Send(ws, []byte(nil))
+					}(ctx.WebsocketConn)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// kill call the dead callback and close the underlying connection, the blocked receives and sends of the handle return errors
+func (h *wsHeartbeat) kill(ctx *Context) {
+	h.dead.Store(true)
+	if h.opts.OnDead != nil {
+		func() {
+			defer func() {
+				err := recover()
+				if err != nil && ctx.Logger != nil {
+					ctx.Logger.Error(fmt.Sprintf("websocket dead callback panic: %s", err))
+				}
+			}()
+			h.opts.OnDead(ctx)
+		}()
+	}
+	if h.conn != nil {
+		_ = h.conn.Close()
+	}
+}
+
+type heartbeatResponseWriter struct {
+	http.ResponseWriter
+	heartbeat *wsHeartbeat
+}
+
+func (w *heartbeatResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("websocket: response does not implement http.Hijacker")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.heartbeat.conn = conn
+	reader := bufio.NewReader(&activityReader{reader: rw.Reader, heartbeat: w.heartbeat})
+	return conn, bufio.NewReadWriter(reader, rw.Writer), nil
+}
+
+type activityReader struct {
+	reader    io.Reader
+	heartbeat *wsHeartbeat
+}
+
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.heartbeat.lastRead.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
@@ -0,0 +1,190 @@
+package easierweb
+
+import (
+	"fmt"
+	"github.com/dpwgc/easierweb/easierwebtest"
+	"golang.org/x/net/websocket"
+	"testing"
+	"time"
+)
+
+// websocket heartbeat test
+
+func TestHeartbeat(t *testing.T) {
+
+	fmt.Println("\n[TestHeartbeat] start")
+
+	dead := make(chan string, 2)
+	exit := make(chan string, 2)
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+		WS: WSOptions{
+			PingInterval: 20 * time.Millisecond,
+			IdleTimeout:  100 * time.Millisecond,
+			OnDead: func(ctx *Context) {
+				dead <- ctx.Query.Get("name")
+			},
+		},
+	})
+	router.WS("/ws", func(ctx *Context) {
+		for {
+			_, err := ctx.Receive()
+			if err != nil {
+				exit <- ctx.Query.Get("name")
+				return
+			}
+		}
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	// the alive client answers the pings while receiving
+	alive, err := tc.GET("/ws").Query("name", "alive").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer alive.Close()
+	go func() {
+		var msg string
+		for websocket.Message.Receive(alive, &msg) == nil {
+		}
+	}()
+
+	// the dead client never reads, the pings are not answered
+	silent, err := tc.GET("/ws").Query("name", "silent").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+
+	select {
+	case name := <-dead:
+		fmt.Println("[TestHeartbeat] dead:", name)
+		if name != "silent" {
+			t.Fatalf("the alive connection is deemed dead")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("the dead connection is not detected")
+	}
+	select {
+	case name := <-exit:
+		if name != "silent" {
+			t.Fatalf("unexpected exit: %s", name)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("the handle of the dead connection does not exit")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case name := <-dead:
+		t.Fatalf("the alive connection is deemed dead: %s", name)
+	default:
+	}
+}
+
+func TestHeartbeatPushOnly(t *testing.T) {
+
+	fmt.Println("\n[TestHeartbeatPushOnly] start")
+
+	dead := make(chan struct{}, 1)
+	receiveErr := make(chan error, 1)
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+		WS: WSOptions{
+			PingInterval: 20 * time.Millisecond,
+			IdleTimeout:  100 * time.Millisecond,
+			PushOnly:     true,
+			OnDead: func(ctx *Context) {
+				dead <- struct{}{}
+			},
+		},
+	})
+	// the handle only sends, it never receives
+	router.WS("/ws", func(ctx *Context) {
+		for i := 0; i < 30; i++ {
+			if ctx.SendString(fmt.Sprint("push ", i)) != nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		_, err := ctx.Receive()
+		receiveErr <- err
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	ws, err := tc.GET("/ws").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// the client answers the pings while reading, the pongs are discarded by the background reader
+	_ = ws.SetReadDeadline(time.Now().Add(3 * time.Second))
+	count := 0
+	var msg string
+	for websocket.Message.Receive(ws, &msg) == nil {
+		count++
+	}
+	fmt.Println("[TestHeartbeatPushOnly] received:", count)
+
+	select {
+	case <-dead:
+		t.Fatal("the push-only connection is deemed dead")
+	default:
+	}
+	if count != 30 {
+		t.Fatal("the push-only connection is closed early, received:", count)
+	}
+	if err = <-receiveErr; err != ErrWSPushOnly {
+		t.Fatal("unexpected receive error:", err)
+	}
+}
+
+func TestReceiveWithTimeout(t *testing.T) {
+
+	fmt.Println("\n[TestReceiveWithTimeout] start")
+
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+	})
+	router.WS("/ws", func(ctx *Context) {
+		_, err := ctx.ReceiveWithTimeout(50 * time.Millisecond)
+		_ = ctx.SendString(fmt.Sprint("timeout: ", IsTimeout(err)))
+		// the deadline is reset after ReceiveWithTimeout
+		msg, err := ctx.ReceiveString()
+		if err != nil {
+			return
+		}
+		_ = ctx.SendString(msg)
+		// wait for the client to close
+		_, _ = ctx.Receive()
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	ws, err := tc.GET("/ws").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	for i, expected := range []string{"timeout: true", "hello"} {
+		if i > 0 {
+			time.Sleep(100 * time.Millisecond)
+			_ = websocket.Message.Send(ws, "hello")
+		}
+		var msg string
+		err = websocket.Message.Receive(ws, &msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("[TestReceiveWithTimeout] receive:", msg)
+		if msg != expected {
+			t.Errorf("unexpected message: %s, expected: %s", msg, expected)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+// HTTPError return it (or panic with it) in handles to respond with the status code, it is recognized by all the response handles and error handles
+type HTTPError struct {
+	Status int `json:"-" xml:"-" yaml:"-"`
+	// application error code, e.g. USER_NOT_FOUND
+	Code    string `json:"code,omitempty" xml:"Code,omitempty" yaml:"code,omitempty"`
+	Msg     string `json:"msg" xml:"Msg" yaml:"msg"`
+	Details any    `json:"details,omitempty" xml:"Details,omitempty" yaml:"details,omitempty"`
+	Cause   error  `json:"-" xml:"-" yaml:"-"`
+}
+
+func (e *HTTPError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("http error %d: %s: %s", e.StatusCode(), e.Msg, e.Cause)
+	}
+	return fmt.Sprintf("http error %d: %s", e.StatusCode(), e.Msg)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Cause
+}
+
+// StatusCode 500 if the status is not set
+func (e *HTTPError) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
+
+func (e *HTTPError) WithCode(code string) *HTTPError {
+	e.Code = code
+	return e
+}
+
+func (e *HTTPError) WithDetails(details any) *HTTPError {
+	e.Details = details
+	return e
+}
+
+func (e *HTTPError) WithCause(err error) *HTTPError {
+	e.Cause = err
+	return e
+}
+
+// NewHTTPError the msg defaults to the status text
+func NewHTTPError(status int, msg string) *HTTPError {
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return &HTTPError{
+		Status: status,
+		Msg:    msg,
+	}
+}
+
+func BadRequest(msg string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, msg)
+}
+
+func Unauthorized(msg string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, msg)
+}
+
+func Forbidden(msg string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, msg)
+}
+
+func NotFound(msg string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, msg)
+}
+
+func Conflict(msg string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, msg)
+}
+
+func UnprocessableEntity(msg string) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, msg)
+}
+
+func TooManyRequests(msg string) *HTTPError {
+	return NewHTTPError(http.StatusTooManyRequests, msg)
+}
+
+func InternalServerError(msg string) *HTTPError {
+	return NewHTTPError(http.StatusInternalServerError, msg)
+}
+
+// ErrorResult get the status code and response body of the errors recognized by the framework (*HTTPError, *BindError, *ValidationError)
+func ErrorResult(err error) (int, any, bool) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode(), httpErr, true
+	}
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		return bindErr.StatusCode(), bindErr, true
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, validationErr, true
+	}
+	return 0, nil, false
+}
+
+// LogServerError log the recognized errors with 5xx status codes, they are written by the handles without panic logging
+// the message includes the cause, e.g. InternalServerError("query failed").WithCause(dbErr)
+func LogServerError(ctx *Context, code int, err error) {
+	if code < http.StatusInternalServerError || ctx == nil || ctx.Logger == nil || err == nil {
+		return
+	}
+	method := ""
+	if ctx.Request != nil {
+		method = ctx.Request.Method
+	}
+	ctx.Logger.Error(err.Error(), slog.String("method", method), slog.String("route", ctx.Route), slog.Int("status", code))
+}
+
+// RFC 7807 problem details
+
+const (
+	ProblemJSONContentType = "application/problem+json"
+	ProblemXMLContentType  = "application/problem+xml"
+)
+
+// Problem the extension members are serialized at the top level
+type Problem struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	type problem Problem
+	marshal, err := json.Marshal((*problem)(p))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(marshal, &members)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// MarshalXML the RFC 7807 xml format: <problem xmlns="urn:ietf:rfc:7807"><status>404</status>...</problem>
+func (p *Problem) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: "problem"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "urn:ietf:rfc:7807"}}}
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	members := []struct {
+		name  string
+		value any
+	}{{"type", p.Type}, {"title", p.Title}, {"status", p.Status}, {"detail", p.Detail}, {"instance", p.Instance}}
+	for _, m := range members {
+		if reflect.ValueOf(m.value).IsZero() {
+			continue
+		}
+		err = encodeProblemMember(e, m.name, m.value)
+		if err != nil {
+			return err
+		}
+	}
+	keys := make([]string, 0, len(p.Extensions))
+	for k := range p.Extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		err = encodeProblemMember(e, k, p.Extensions[k])
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// maps are encoded as nested elements, arrays are encoded as <i> elements
+func encodeProblemMember(e *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if value == nil {
+		return e.EncodeElement("", start)
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		err := e.EncodeToken(start)
+		if err != nil {
+			return err
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			err = encodeProblemMember(e, fmt.Sprint(k.Interface()), v.MapIndex(k).Interface())
+			if err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		err := e.EncodeToken(start)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			err = encodeProblemMember(e, "i", v.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	}
+	return e.EncodeElement(value, start)
+}
+
+// NewProblem create the problem details of the error, the instance is the request uri
+// the members of the recognized errors are added as extensions (code/details, source/field, fields)
+// other errors are treated as 500 errors, and the detail is shown only if showError is true
+func NewProblem(ctx *Context, err any, showError bool) *Problem {
+	p := &Problem{
+		Type:       "about:blank",
+		Status:     http.StatusInternalServerError,
+		Extensions: make(map[string]any),
+	}
+	if ctx != nil && ctx.Request != nil {
+		p.Instance = ctx.Request.URL.RequestURI()
+	}
+	e, _ := err.(error)
+	if status, _, ok := ErrorResult(e); ok {
+		p.Status = status
+		p.Detail = e.Error()
+		var httpErr *HTTPError
+		var bindErr *BindError
+		var validationErr *ValidationError
+		switch {
+		case errors.As(e, &httpErr):
+			p.Detail = httpErr.Msg
+			if httpErr.Code != "" {
+				p.Extensions["code"] = httpErr.Code
+			}
+			if httpErr.Details != nil {
+				p.Extensions["details"] = httpErr.Details
+			}
+		case errors.As(e, &bindErr):
+			p.Detail = bindErr.Msg
+			p.Extensions["source"] = bindErr.Source
+			if bindErr.Field != "" {
+				p.Extensions["field"] = bindErr.Field
+			}
+		case errors.As(e, &validationErr):
+			p.Detail = validationErr.Msg
+			p.Extensions["fields"] = validationErr.Fields
+		}
+	} else if showError {
+		p.Detail = fmt.Sprintf("%s", err)
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+// WriteProblem write the problem details in application/problem+json format
+func (c *Context) WriteProblem(problem *Problem) {
+	if c.written {
+		return
+	}
+	marshal, err := json.Marshal(problem)
+	if err != nil {
+		panic(err)
+	}
+	c.SetContentType(ProblemJSONContentType)
+	c.Write(problem.Status, marshal)
+}
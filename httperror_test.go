@@ -0,0 +1,105 @@
+package easierweb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// http error test
+
+func TestHTTPError(t *testing.T) {
+
+	fmt.Println("\n[TestHTTPError] start")
+
+	for _, problemDetails := range []bool{false, true} {
+		router := New(RouterOptions{
+			ProblemDetails:    problemDetails,
+			CloseConsolePrint: true,
+		})
+		router.EasyGET("/user/:id", func(ctx *Context) (*routerTestDTO, error) {
+			return nil, NotFound("user does not exist").WithCode("USER_NOT_FOUND").WithCause(errors.New("sql: no rows"))
+		})
+		router.GET("/conflict", func(ctx *Context) {
+			panic(Conflict("").WithDetails([]string{"name"}))
+		})
+		router.GET("/panic", func(ctx *Context) {
+			panic("boom")
+		})
+
+		cases := []struct {
+			uri         string
+			code        int
+			contentType string
+			member      string
+			value       any
+		}{
+			{"/user/1", http.StatusNotFound, "application/json; charset=utf-8", "code", "USER_NOT_FOUND"},
+			{"/conflict", http.StatusConflict, "application/json; charset=utf-8", "msg", "Conflict"},
+			{"/panic", http.StatusInternalServerError, "text/plain; charset=utf-8", "", nil},
+		}
+		if problemDetails {
+			cases = []struct {
+				uri         string
+				code        int
+				contentType string
+				member      string
+				value       any
+			}{
+				{"/user/1?a=1", http.StatusNotFound, ProblemJSONContentType, "instance", "/user/1?a=1"},
+				{"/user/1", http.StatusNotFound, ProblemJSONContentType, "code", "USER_NOT_FOUND"},
+				{"/conflict", http.StatusConflict, ProblemJSONContentType, "title", "Conflict"},
+				{"/panic", http.StatusInternalServerError, ProblemJSONContentType, "detail", "boom"},
+			}
+		}
+		for _, c := range cases {
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, httptest.NewRequest("GET", c.uri, nil))
+			fmt.Println("[TestHTTPError]", c.uri, "->", res.Code, res.Header().Get("Content-Type"), res.Body.String())
+			if res.Code != c.code || res.Header().Get("Content-Type") != c.contentType {
+				t.Errorf("%s unexpected response %d %s", c.uri, res.Code, res.Header().Get("Content-Type"))
+			}
+			if c.member == "" {
+				continue
+			}
+			body := map[string]any{}
+			_ = json.Unmarshal(res.Body.Bytes(), &body)
+			if body[c.member] != c.value {
+				t.Errorf("%s member %s is %v, expected %v", c.uri, c.member, body[c.member], c.value)
+			}
+		}
+	}
+
+	// the 5xx http errors are logged with the cause, returned or panicked
+	logs := &bytes.Buffer{}
+	router := New(RouterOptions{
+		Logger:            slog.New(slog.NewTextHandler(logs, nil)),
+		CloseConsolePrint: true,
+	})
+	router.EasyGET("/returned", func(ctx *Context) error {
+		return InternalServerError("query failed").WithCause(errors.New("db is down"))
+	})
+	router.GET("/panicked", func(ctx *Context) {
+		panic(InternalServerError("query failed").WithCause(errors.New("db is gone")))
+	})
+	router.GET("/not-found", func(ctx *Context) {
+		panic(NotFound(""))
+	})
+	for _, uri := range []string{"/returned", "/panicked", "/not-found"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", uri, nil))
+	}
+	fmt.Print("[TestHTTPError] logs: ", logs.String())
+	if !bytes.Contains(logs.Bytes(), []byte("db is down")) || !bytes.Contains(logs.Bytes(), []byte("db is gone")) || bytes.Contains(logs.Bytes(), []byte("404")) {
+		t.Errorf("unexpected server error logs: %s", logs.String())
+	}
+
+	err := fmt.Errorf("wrapped: %w", BadRequest("invalid id"))
+	if code, _, ok := ErrorResult(err); !ok || code != http.StatusBadRequest {
+		t.Errorf("wrapped http error is not recognized: %d %v", code, ok)
+	}
+}
@@ -0,0 +1,381 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"errors"
+	"golang.org/x/net/websocket"
+	"sort"
+	"sync"
+	"time"
+)
+
+// websocket hub, it tracks the live websocket connections and their rooms
+// the connections are removed automatically when the websocket handles return
+// each connection has a bounded send queue drained by its own writer goroutine, so a stalled client does not block the others
+
+type HubOptions struct {
+	// queued messages of each connection, default: 64
+	QueueSize int
+	// drop the messages (SlowConsumerDrop, default) or close the connection (SlowConsumerDisconnect) when its queue is full
+	SlowConsumer SlowConsumerPolicy
+}
+
+var (
+	ErrHubQueueFull  = errors.New("hub connection send queue is full")
+	ErrHubConnClosed = errors.New("hub connection is closed")
+)
+
+type Hub struct {
+	queueSize    int
+	slowConsumer SlowConsumerPolicy
+	conns        map[*Context]*HubConn
+	rooms        map[string]map[*HubConn]struct{}
+	mutex        sync.RWMutex
+}
+
+// HubConn a websocket connection registered in the hub
+type HubConn struct {
+	ctx   *Context
+	route string
+	ws    *websocket.Conn
+	// the write timeout of the websocket options
+	writeTimeout time.Duration
+	slowConsumer SlowConsumerPolicy
+	queue        chan []byte
+	done         chan struct{}
+	stopOnce     sync.Once
+	rooms        map[string]struct{}
+	meta         map[string]any
+	mutex        sync.RWMutex
+}
+
+func NewHub(opts ...HubOptions) *Hub {
+	h := &Hub{
+		queueSize: 64,
+		conns:     make(map[*Context]*HubConn),
+		rooms:     make(map[string]map[*HubConn]struct{}),
+	}
+	for _, v := range opts {
+		if v.QueueSize > 0 {
+			h.queueSize = v.QueueSize
+		}
+		h.slowConsumer = v.SlowConsumer
+	}
+	return h
+}
+
+// Register add the websocket connection to the hub, it is removed when the handle returns
+// return the registered connection if it is already registered
+func (h *Hub) Register(ctx *Context) *HubConn {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.register(ctx)
+}
+
+func (h *Hub) register(ctx *Context) *HubConn {
+	if ctx.WebsocketConn == nil {
+		panic(errors.New("hub: " + ctx.Route + " is not a websocket route"))
+	}
+	if conn, has := h.conns[ctx]; has {
+		return conn
+	}
+	conn := &HubConn{
+		ctx:          ctx,
+		route:        ctx.Route,
+		ws:           ctx.WebsocketConn,
+		writeTimeout: ctx.wsWriteTimeout,
+		slowConsumer: h.slowConsumer,
+		queue:        make(chan []byte, h.queueSize),
+		done:         make(chan struct{}),
+		rooms:        make(map[string]struct{}),
+		meta:         make(map[string]any),
+	}
+	h.conns[ctx] = conn
+	go conn.writeLoop()
+	ctx.OnClose(func() {
+		h.Unregister(ctx)
+	})
+	return conn
+}
+
+// Unregister remove the connection from the hub and all its rooms
+func (h *Hub) Unregister(ctx *Context) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	conn, has := h.conns[ctx]
+	if !has {
+		return
+	}
+	for room := range conn.rooms {
+		h.leave(conn, room)
+	}
+	delete(h.conns, ctx)
+	conn.stop()
+}
+
+// Conn get the registered connection of the context, nil if it is not registered
+func (h *Hub) Conn(ctx *Context) *HubConn {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.conns[ctx]
+}
+
+// Join add the connection to the rooms, the connection is registered if it is not registered
+// the registration and the joining are done under one lock, so a racing Unregister does not leave the connection in the rooms
+func (h *Hub) Join(ctx *Context, rooms ...string) *HubConn {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	conn := h.register(ctx)
+	h.join(conn, rooms)
+	return conn
+}
+
+// join the connections no longer registered are not added to the rooms
+func (h *Hub) join(conn *HubConn, rooms []string) {
+	if h.conns[conn.ctx] != conn {
+		return
+	}
+	for _, room := range rooms {
+		members, has := h.rooms[room]
+		if !has {
+			members = make(map[*HubConn]struct{})
+			h.rooms[room] = members
+		}
+		members[conn] = struct{}{}
+		conn.mutex.Lock()
+		conn.rooms[room] = struct{}{}
+		conn.mutex.Unlock()
+	}
+}
+
+// Leave remove the connection from the rooms, the connection is still registered
+func (h *Hub) Leave(ctx *Context, rooms ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	conn, has := h.conns[ctx]
+	if !has {
+		return
+	}
+	for _, room := range rooms {
+		h.leave(conn, room)
+	}
+}
+
+func (h *Hub) leave(conn *HubConn, room string) {
+	if members, has := h.rooms[room]; has {
+		delete(members, conn)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+	conn.mutex.Lock()
+	delete(conn.rooms, room)
+	conn.mutex.Unlock()
+}
+
+// Broadcast queue the message to all the connections, except the excluded contexts (usually the sender)
+// it does not wait for the writes, the errors of the full queues (ErrHubQueueFull) and the closed connections are joined
+func (h *Hub) Broadcast(msg []byte, exclude ...*Context) error {
+	return sendAll(h.Conns(), msg, exclude)
+}
+
+// BroadcastRoom send the message to the connections in the room, except the excluded contexts
+func (h *Hub) BroadcastRoom(room string, msg []byte, exclude ...*Context) error {
+	return sendAll(h.RoomConns(room), msg, exclude)
+}
+
+// BroadcastJSON send the json message to all the connections, or to the connections in the room if the room is not empty
+func (h *Hub) BroadcastJSON(room string, obj any, exclude ...*Context) error {
+	marshal, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if room == "" {
+		return h.Broadcast(marshal, exclude...)
+	}
+	return h.BroadcastRoom(room, marshal, exclude...)
+}
+
+// the messages are queued without holding the hub lock, the message slice is shared by the connections, so it should not be modified
+func sendAll(conns []*HubConn, msg []byte, exclude []*Context) error {
+	var errs []error
+	for _, conn := range conns {
+		excluded := false
+		for _, ctx := range exclude {
+			if conn.ctx == ctx {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+		err := conn.Send(msg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Conns all the registered connections
+func (h *Hub) Conns() []*HubConn {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	conns := make([]*HubConn, 0, len(h.conns))
+	for _, conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// RoomConns the connections in the room
+func (h *Hub) RoomConns(room string) []*HubConn {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	conns := make([]*HubConn, 0, len(h.rooms[room]))
+	for conn := range h.rooms[room] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// Rooms the names of the rooms with connections, sorted
+func (h *Hub) Rooms() []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	rooms := make([]string, 0, len(h.rooms))
+	for room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// Count the number of the registered connections
+func (h *Hub) Count() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.conns)
+}
+
+// RoomCount the number of the connections in the room
+func (h *Hub) RoomCount(room string) int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Send queue the message of the connection, it can be called by other goroutines and does not wait for the write
+// return ErrHubQueueFull if the queue is full (the connection is closed if the policy is SlowConsumerDisconnect)
+// the connection is closed if a write fails, the message should not be modified after sending
+func (c *HubConn) Send(msg []byte) error {
+	select {
+	case <-c.done:
+		return ErrHubConnClosed
+	default:
+	}
+	select {
+	case c.queue <- msg:
+		return nil
+	default:
+		if c.slowConsumer == SlowConsumerDisconnect {
+			c.disconnect()
+		}
+		return ErrHubQueueFull
+	}
+}
+
+// writeLoop write the queued messages until the connection is unregistered or a write fails
+func (c *HubConn) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.queue:
+			err := c.write(msg)
+			if err != nil {
+				c.disconnect()
+				return
+			}
+		}
+	}
+}
+
+func (c *HubConn) write(msg []byte) error {
+	if c.writeTimeout > 0 {
+		err := c.ws.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := c.ws.Write(msg)
+	return err
+}
+
+func (c *HubConn) stop() bool {
+	stopped := false
+	c.stopOnce.Do(func() {
+		close(c.done)
+		stopped = true
+	})
+	return stopped
+}
+
+// disconnect stop the writer and close the connection, the receives of the handle return errors and the connection is unregistered
+// the expired write deadline unblocks the stalled write, so closing does not wait for it
+// the context is marked closed, so the close after the handle returns is not reported as an error
+func (c *HubConn) disconnect() {
+	// the connection has been unregistered, the context may be reused
+	if !c.stop() {
+		return
+	}
+	c.ctx.wsClosed.Store(true)
+	_ = c.ws.SetWriteDeadline(time.Now())
+	go func() {
+		_ = c.ws.Close()
+	}()
+}
+
+func (c *HubConn) SendString(text string) error {
+	return c.Send([]byte(text))
+}
+
+func (c *HubConn) SendJSON(obj any) error {
+	marshal, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.Send(marshal)
+}
+
+// Set set the metadata of the connection, e.g. user id
+func (c *HubConn) Set(key string, value any) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.meta[key] = value
+}
+
+func (c *HubConn) Get(key string) (any, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	value, has := c.meta[key]
+	return value, has
+}
+
+// Rooms the rooms of the connection, sorted
+func (c *HubConn) Rooms() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// Route the route of the connection
+func (c *HubConn) Route() string {
+	return c.route
+}
@@ -0,0 +1,189 @@
+package easierweb
+
+import (
+	"fmt"
+	"github.com/dpwgc/easierweb/easierwebtest"
+	"golang.org/x/net/websocket"
+	"testing"
+	"time"
+)
+
+// websocket hub test
+
+func TestHub(t *testing.T) {
+
+	fmt.Println("\n[TestHub] start")
+
+	hub := NewHub()
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+	})
+	router.WS("/chat/:room", func(ctx *Context) {
+		conn := hub.Join(ctx, ctx.Path.Get("room"))
+		conn.Set("name", ctx.Query.Get("name"))
+		for {
+			msg, err := ctx.ReceiveString()
+			if err != nil {
+				return
+			}
+			name, _ := conn.Get("name")
+			if msg == "all" {
+				_ = hub.Broadcast([]byte(fmt.Sprintf("%s: %s", name, msg)), ctx)
+				continue
+			}
+			_ = hub.BroadcastRoom(ctx.Path.Get("room"), []byte(fmt.Sprintf("%s: %s", name, msg)), ctx)
+		}
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	dial := func(room, name string) *websocket.Conn {
+		ws, err := tc.GET("/chat/"+room).Query("name", name).WS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ws
+	}
+	a := dial("go", "a")
+	b := dial("go", "b")
+	c := dial("rust", "c")
+	waitHub(t, func() bool { return hub.Count() == 3 })
+	if rooms := hub.Rooms(); fmt.Sprint(rooms) != "[go rust]" || hub.RoomCount("go") != 2 {
+		t.Fatalf("unexpected rooms: %v", rooms)
+	}
+
+	receive := func(ws *websocket.Conn, expected string) {
+		var msg string
+		err := websocket.Message.Receive(ws, &msg)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		fmt.Println("[TestHub] receive:", msg)
+		if msg != expected {
+			t.Errorf("unexpected message: %s, expected: %s", msg, expected)
+		}
+	}
+	_ = websocket.Message.Send(a, "hello")
+	receive(b, "a: hello")
+	_ = websocket.Message.Send(c, "all")
+	// the messages are written by the writer goroutines of the connections, they are received in any order
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		receive(a, "c: all")
+	}()
+	receive(b, "c: all")
+	<-received
+
+	// the connection is removed from the hub and the room when the handle returns
+	_ = b.Close()
+	waitHub(t, func() bool { return hub.Count() == 2 && hub.RoomCount("go") == 1 })
+	_ = a.Close()
+	_ = c.Close()
+	waitHub(t, func() bool { return hub.Count() == 0 && len(hub.Rooms()) == 0 })
+
+	// the unregistered connections are not added to the rooms
+	hub.mutex.Lock()
+	hub.join(&HubConn{ctx: &Context{}, rooms: make(map[string]struct{})}, []string{"go"})
+	hub.mutex.Unlock()
+	if hub.RoomCount("go") != 0 {
+		t.Error("the unregistered connection joins the room")
+	}
+}
+
+func TestHubSlowConsumer(t *testing.T) {
+
+	fmt.Println("\n[TestHubSlowConsumer] start")
+
+	hub := NewHub(HubOptions{QueueSize: 1, SlowConsumer: SlowConsumerDisconnect})
+	exit := make(chan string, 2)
+	handleErr := make(chan any, 2)
+	router := New(RouterOptions{
+		ErrorHandle: func(ctx *Context, err any) {
+			handleErr <- err
+		},
+		CloseConsolePrint: true,
+	})
+	router.WS("/ws", func(ctx *Context) {
+		hub.Join(ctx, "all")
+		_, _ = ctx.Receive()
+		exit <- ctx.Query.Get("name")
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	// the fast client keeps reading, the slow client never reads
+	fast, err := tc.GET("/ws").Query("name", "fast").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.Close()
+	received := make(chan int, 1)
+	go func() {
+		n := 0
+		var msg string
+		for websocket.Message.Receive(fast, &msg) == nil {
+			n++
+			if n == 10 {
+				received <- n
+			}
+		}
+	}()
+	slow, err := tc.GET("/ws").Query("name", "slow").WS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	waitHub(t, func() bool { return hub.Count() == 2 })
+
+	// the broadcasts do not wait for the stalled client
+	var errs []error
+	for i := 0; i < 10; i++ {
+		err = hub.BroadcastRoom("all", []byte(fmt.Sprint(i)))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	fmt.Println("[TestHubSlowConsumer] broadcast errors:", len(errs))
+	if len(errs) == 0 {
+		t.Error("the full queue of the slow client is not reported")
+	}
+	select {
+	case n := <-received:
+		fmt.Println("[TestHubSlowConsumer] fast client received:", n)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the fast client is blocked by the slow client")
+	}
+
+	// the slow client is disconnected and removed from the hub
+	select {
+	case name := <-exit:
+		if name != "slow" {
+			t.Fatalf("unexpected exit: %s", name)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("the slow client is not disconnected")
+	}
+	waitHub(t, func() bool { return hub.Count() == 1 && hub.RoomCount("all") == 1 })
+	// the disconnected connection is closed without errors
+	select {
+	case err := <-handleErr:
+		t.Fatalf("the disconnected connection reports an error: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func waitHub(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 300; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the hub condition is not met")
+}
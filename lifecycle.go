@@ -0,0 +1,150 @@
+package easierweb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Hook lifecycle hook, OnStart hooks run before the server starts listening, OnShutdown hooks run after the connections are drained
+type Hook func(ctx context.Context) error
+
+func (r *Router) OnStart(hooks ...Hook) *Router {
+	r.startHooks = append(r.startHooks, hooks...)
+	return r
+}
+
+func (r *Router) OnShutdown(hooks ...Hook) *Router {
+	r.shutdownHooks = append(r.shutdownHooks, hooks...)
+	return r
+}
+
+// Shutdown stop accepting new connections, notify the websocket and sse connections (the request context is canceled, the websocket connection is closed),
+// wait for the active requests to finish, and then run the OnShutdown hooks
+// if ctx has no deadline, RouterOptions.ShutdownTimeout is used, the remaining connections are closed forcibly when the deadline is exceeded
+// the hooks get their own context (the values of ctx, not canceled with it), canceled after RouterOptions.ShutdownHookTimeout
+func (r *Router) Shutdown(ctx context.Context) error {
+	if _, has := ctx.Deadline(); !has && r.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.shutdownTimeout)
+		defer cancel()
+	}
+
+	r.lifecycleMutex.Lock()
+	r.shuttingDown = true
+	server := r.server
+	for c, cancel := range r.conns {
+		cancel()
+		if c.WebsocketConn != nil {
+			_ = c.WebsocketConn.Close()
+		}
+	}
+	r.lifecycleMutex.Unlock()
+
+	var errs []error
+	if server != nil {
+		err := server.Shutdown(ctx)
+		if err != nil {
+			errs = append(errs, err, server.Close())
+		}
+	}
+
+	// hijacked websocket connections are not tracked by the http server
+	drained := make(chan struct{})
+	go func() {
+		r.connWaitGroup.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	// the drain context may be canceled already, the hooks still have time to flush and close the resources
+	hookCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.shutdownHookTimeout)
+	defer cancel()
+	for _, hook := range r.shutdownHooks {
+		errs = append(errs, hook(hookCtx))
+	}
+	return errors.Join(errs...)
+}
+
+// RunWithSignals start server and shut down gracefully when one of the signals is received (default: SIGINT, SIGTERM)
+func (r *Router) RunWithSignals(addr string, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, signals...)
+	defer signal.Stop(quit)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- r.Run(addr)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case sig := <-quit:
+		r.logger.Info("shutting down server, signal: " + sig.String())
+	}
+
+	err := r.Shutdown(context.Background())
+	if serveErr := <-serveErr; serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		return errors.Join(serveErr, err)
+	}
+	return err
+}
+
+func (r *Router) runStartHooks(server *http.Server) error {
+	r.lifecycleMutex.Lock()
+	r.server = server
+	r.shuttingDown = false
+	r.lifecycleMutex.Unlock()
+	for _, hook := range r.startHooks {
+		err := hook(context.Background())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// track the websocket or sse connection until the handle returns, the request context is canceled on shutdown
+func (r *Router) trackConn(ctx *Context) (release func()) {
+	connCtx, cancel := context.WithCancel(ctx.Request.Context())
+	ctx.Request = ctx.Request.WithContext(connCtx)
+
+	r.lifecycleMutex.Lock()
+	defer r.lifecycleMutex.Unlock()
+	if r.shuttingDown {
+		cancel()
+		return func() {}
+	}
+	if r.conns == nil {
+		r.conns = make(map[*Context]context.CancelFunc)
+	}
+	r.conns[ctx] = cancel
+	r.connWaitGroup.Add(1)
+	return func() {
+		r.lifecycleMutex.Lock()
+		delete(r.conns, ctx)
+		r.lifecycleMutex.Unlock()
+		cancel()
+		r.connWaitGroup.Done()
+	}
+}
+
+func (r *Router) isShuttingDown() bool {
+	r.lifecycleMutex.Lock()
+	defer r.lifecycleMutex.Unlock()
+	return r.shuttingDown
+}
@@ -0,0 +1,112 @@
+package easierweb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graceful shutdown test
+
+func TestShutdown(t *testing.T) {
+
+	fmt.Println("\n[TestShutdown] start")
+
+	var events []string
+	router := New(RouterOptions{
+		RootPath:          "/test/shutdown",
+		ShutdownTimeout:   3 * time.Second,
+		CloseConsolePrint: true,
+	})
+	router.OnShutdown(func(ctx context.Context) error {
+		events = append(events, "flush")
+		return nil
+	}, func(ctx context.Context) error {
+		events = append(events, "close")
+		return nil
+	})
+
+	router.SSE("/sse", func(ctx *Context) {
+		err := ctx.Push("data: ready\n\n")
+		if err != nil {
+			panic(err)
+		}
+		<-ctx.Request.Context().Done()
+		events = append(events, "sse done")
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	router.server = server.Config
+
+	res, err := http.Get(server.URL + "/test/shutdown/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	if err != nil || !strings.Contains(line, "ready") {
+		t.Fatalf("sse is not ready: %s %v", line, err)
+	}
+
+	err = router.Shutdown(context.Background())
+	fmt.Println("[TestShutdown] events:", events, "error:", err)
+	if err != nil {
+		t.Errorf("shutdown error: %v", err)
+	}
+	if strings.Join(events, ",") != "sse done,flush,close" {
+		t.Errorf("unexpected shutdown events: %v", events)
+	}
+}
+
+func TestShutdownHookContext(t *testing.T) {
+
+	fmt.Println("\n[TestShutdownHookContext] start")
+
+	var hookErr error
+	router := New(RouterOptions{
+		ShutdownHookTimeout: time.Second,
+		CloseConsolePrint:   true,
+	}).OnShutdown(func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		hookErr = ctx.Err()
+		if !hasDeadline {
+			hookErr = errors.New("the hook context has no deadline")
+		}
+		return nil
+	})
+
+	// the hooks are not canceled with the drain context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = router.Shutdown(ctx)
+	fmt.Println("[TestShutdownHookContext] hook context error:", hookErr)
+	if hookErr != nil {
+		t.Errorf("unexpected hook context error: %v", hookErr)
+	}
+}
+
+func TestStartHook(t *testing.T) {
+
+	fmt.Println("\n[TestStartHook] start")
+
+	hookErr := errors.New("db is unavailable")
+	router := New(RouterOptions{
+		CloseConsolePrint: true,
+	}).OnStart(func(ctx context.Context) error {
+		return hookErr
+	})
+
+	// the server does not start listening if a start hook fails
+	err := router.Run("127.0.0.1:0")
+	fmt.Println("[TestStartHook] error:", err)
+	if !errors.Is(err, hookErr) {
+		t.Errorf("unexpected run error: %v", err)
+	}
+}
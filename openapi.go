@@ -0,0 +1,435 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type OpenAPIOptions struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []string
+}
+
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIInfo                             `json:"info" yaml:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths" yaml:"paths"`
+	Components *OpenAPIComponents                      `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url" yaml:"url"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name     string         `json:"name" yaml:"name"`
+	In       string         `json:"in" yaml:"in"`
+	Required bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description" yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                    `json:"format,omitempty" yaml:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+func (d *OpenAPI) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func (d *OpenAPI) YAML() ([]byte, error) {
+	return yaml.Marshal(d)
+}
+
+// easy api registration info, used to generate the openapi document
+
+type easyAPI struct {
+	method   string
+	route    string
+	funcName string
+	reqType  reflect.Type
+	resType  reflect.Type
+}
+
+func (r *Router) addEasyAPI(method, route string, plan *easyPlan) {
+	r.easyAPIs = append(r.easyAPIs, easyAPI{
+		method:   method,
+		route:    route,
+		funcName: plan.funcName,
+		reqType:  plan.reqType,
+		resType:  plan.resType,
+	})
+}
+
+// OpenAPI generate an OpenAPI 3.1 document from all registered easy handles
+func (r *Router) OpenAPI(opts ...OpenAPIOptions) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:   "easierweb",
+			Version: "1.0.0",
+		},
+		Paths: make(map[string]map[string]*OpenAPIOperation),
+	}
+	for _, v := range opts {
+		if v.Title != "" {
+			doc.Info.Title = v.Title
+		}
+		if v.Version != "" {
+			doc.Info.Version = v.Version
+		}
+		if v.Description != "" {
+			doc.Info.Description = v.Description
+		}
+		for _, s := range v.Servers {
+			doc.Servers = append(doc.Servers, OpenAPIServer{URL: s})
+		}
+	}
+
+	schemas := newOpenAPISchemas()
+	operationIDs := make(map[string]int)
+
+	for _, api := range r.easyAPIs {
+		path, pathParams := openAPIPath(api.route)
+		op := &OpenAPIOperation{
+			OperationID: openAPIOperationID(api.method, api.funcName, operationIDs),
+			Responses:   make(map[string]*OpenAPIResponse),
+		}
+		for _, p := range pathParams {
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name:     p,
+				In:       "path",
+				Required: true,
+				Schema:   &OpenAPISchema{Type: "string"},
+			})
+		}
+		if api.reqType != nil {
+			isBody := api.method == MethodPOST || api.method == MethodPUT || api.method == MethodPATCH
+			op.Parameters = append(op.Parameters, openAPIParameters(api.reqType, !isBody, pathParams)...)
+			if isBody {
+				op.RequestBody = &OpenAPIRequestBody{
+					Content: map[string]*OpenAPIMediaType{
+						"application/json": {Schema: openAPISchema(api.reqType, "json", schemas)},
+					},
+				}
+			}
+		}
+		if api.resType != nil {
+			op.Responses["200"] = &OpenAPIResponse{
+				Description: http.StatusText(http.StatusOK),
+				Content: map[string]*OpenAPIMediaType{
+					"application/json": {Schema: openAPISchema(api.resType, "json", schemas)},
+				},
+			}
+		} else {
+			op.Responses["204"] = &OpenAPIResponse{
+				Description: http.StatusText(http.StatusNoContent),
+			}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(api.method)] = op
+	}
+
+	if len(schemas.schemas) > 0 {
+		doc.Components = &OpenAPIComponents{Schemas: schemas.schemas}
+	}
+	return doc
+}
+
+// OpenAPIRoute serve the OpenAPI document, in YAML format if the path ends with .yaml or .yml, otherwise in JSON format
+func (r *Router) OpenAPIRoute(path string, opts ...OpenAPIOptions) *Router {
+	isYAML := strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+	return r.GET(path, func(ctx *Context) {
+		doc := r.OpenAPI(opts...)
+		if isYAML {
+			ctx.WriteYAML(http.StatusOK, doc)
+		} else {
+			ctx.WriteJSON(http.StatusOK, doc)
+		}
+	})
+}
+
+// convert httprouter path syntax (:id, *filepath) into openapi path templating ({id}, {filepath})
+func openAPIPath(route string) (string, []string) {
+	var params []string
+	segments := strings.Split(route, "/")
+	for i, s := range segments {
+		if len(s) > 1 && (s[0] == ':' || s[0] == '*') {
+			params = append(params, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func openAPIOperationID(method, funcName string, used map[string]int) string {
+	name := funcName
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	if name == "" {
+		name = "handle"
+	}
+	id := strings.ToLower(method) + strings.ToUpper(name[:1]) + name[1:]
+	used[id]++
+	if used[id] > 1 {
+		return id + strconv.Itoa(used[id])
+	}
+	return id
+}
+
+// fields with query/header/cookie tags are parameters, other fields are query parameters (mapstructure tag) if there is no request body
+func openAPIParameters(t reflect.Type, untaggedQuery bool, pathParams []string) []*OpenAPIParameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []*OpenAPIParameter
+	for _, f := range openAPIFields(t, "mapstructure") {
+		if f.source != "" {
+			if f.source == BindSourcePath || f.source == BindSourceForm {
+				continue
+			}
+			params = append(params, &OpenAPIParameter{
+				Name:   f.sourceName,
+				In:     f.source,
+				Schema: openAPISchema(f.typ, "mapstructure", nil),
+			})
+			continue
+		}
+		if !untaggedQuery {
+			continue
+		}
+		isPath := false
+		for _, p := range pathParams {
+			if p == f.name {
+				isPath = true
+			}
+		}
+		if isPath {
+			continue
+		}
+		params = append(params, &OpenAPIParameter{
+			Name:   f.name,
+			In:     "query",
+			Schema: openAPISchema(f.typ, "mapstructure", nil),
+		})
+	}
+	return params
+}
+
+type openAPIField struct {
+	name      string
+	typ       reflect.Type
+	omitempty bool
+	tagged    bool
+	// the binding source tag (path/query/header/cookie/form) and its name
+	source     string
+	sourceName string
+}
+
+// collect the exported fields of a struct, using the tag to get the field name, anonymous struct fields are flattened
+func openAPIFields(t reflect.Type, tag string) []openAPIField {
+	var fields []openAPIField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tagValue := f.Tag.Get(tag)
+		if tagValue == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tagValue, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, openAPIFields(ft, tag)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := openAPIField{
+			name:      name,
+			typ:       f.Type,
+			omitempty: strings.Contains(opts, "omitempty") || f.Type.Kind() == reflect.Ptr,
+			tagged:    tagValue != "",
+		}
+		for _, source := range bindSourceTags {
+			if sourceName := f.Tag.Get(source); sourceName != "" && sourceName != "-" {
+				field.source = source
+				field.sourceName = sourceName
+				break
+			}
+		}
+		if _, has := f.Tag.Lookup("default"); has {
+			field.omitempty = true
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// the component schemas, keyed by the types, so the same-named types of different packages do not overwrite each other
+type openAPISchemas struct {
+	schemas map[string]*OpenAPISchema
+	names   map[reflect.Type]string
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{
+		schemas: make(map[string]*OpenAPISchema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// the component key of a named struct, the names used by another type are qualified by the package name
+func (s *openAPISchemas) name(t reflect.Type) string {
+	if name, has := s.names[t]; has {
+		return name
+	}
+	name := openAPISchemaName(t.Name())
+	if _, used := s.schemas[name]; used {
+		name = openAPISchemaName(path.Base(t.PkgPath()) + "." + t.Name())
+	}
+	for i, base := 2, name; ; i++ {
+		if _, used := s.schemas[name]; !used {
+			break
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+	s.names[t] = name
+	return name
+}
+
+// build the schema of a type, named structs are put into the components when schemas is not nil
+func openAPISchema(t reflect.Type, tag string, schemas *openAPISchemas) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: openAPISchema(t.Elem(), tag, schemas)}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: openAPISchema(t.Elem(), tag, schemas)}
+	case reflect.Struct:
+		if schemas != nil && t.Name() != "" {
+			_, has := schemas.names[t]
+			name := schemas.name(t)
+			if !has {
+				// placeholder to break recursive references
+				schemas.schemas[name] = &OpenAPISchema{}
+				*schemas.schemas[name] = *openAPIStructSchema(t, tag, schemas)
+			}
+			return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+		}
+		return openAPIStructSchema(t, tag, schemas)
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+func openAPIStructSchema(t reflect.Type, tag string, schemas *openAPISchemas) *OpenAPISchema {
+	s := &OpenAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*OpenAPISchema),
+	}
+	for _, f := range openAPIFields(t, tag) {
+		// the fields bound from other sources are not part of the body
+		if f.source != "" && !f.tagged {
+			continue
+		}
+		s.Properties[f.name] = openAPISchema(f.typ, tag, schemas)
+		if !f.omitempty {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+func openAPISchemaName(name string) string {
+	// generic type names contain characters that are not allowed in component keys
+	return strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.' || c == '-' {
+			return c
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,123 @@
+package easierweb
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// openapi test
+
+func TestOpenAPI(t *testing.T) {
+
+	fmt.Println("\n[TestOpenAPI] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/openapi",
+		CloseConsolePrint: true,
+	})
+
+	router.EasyGET("/easy/get/:id", routerTestEasyQueryAPI)
+	router.EasyPOST("/easy/post", routerTestEasySaveAPI)
+	router.EasyDELETE("/easy/delete/:id", routerTestEasyDelAPI)
+	router.GET("/basic", routerTestAPI)
+
+	doc := router.OpenAPI(OpenAPIOptions{
+		Title:   "test",
+		Version: "1.0.0",
+	})
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "test" {
+		t.Fatal("openapi document info error")
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatal("openapi document paths error:", len(doc.Paths))
+	}
+
+	get := doc.Paths["/test/openapi/easy/get/{id}"]["get"]
+	if get == nil || get.OperationID != "getRouterTestEasyQueryAPI" {
+		t.Fatal("openapi get operation error")
+	}
+	if get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" || len(get.Parameters) != 7 {
+		t.Fatal("openapi get parameters error")
+	}
+	if get.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/routerTestDTO" {
+		t.Fatal("openapi get response error")
+	}
+
+	post := doc.Paths["/test/openapi/easy/post"]["post"]
+	if post == nil || post.RequestBody == nil {
+		t.Fatal("openapi post operation error")
+	}
+
+	del := doc.Paths["/test/openapi/easy/delete/{id}"]["delete"]
+	if del == nil || del.Responses["204"] == nil {
+		t.Fatal("openapi delete operation error")
+	}
+
+	schema := doc.Components.Schemas["routerTestDTO"]
+	if schema == nil || schema.Properties["int64"].Type != "integer" || schema.Properties["string"].Type != "string" {
+		t.Fatal("openapi schema error")
+	}
+
+	j, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("[TestOpenAPI] json ->", string(j))
+
+	y, err := doc.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("[TestOpenAPI] yaml ->", string(y))
+
+	fmt.Println("\n[TestOpenAPI] end")
+}
+
+func TestOpenAPISchemaNames(t *testing.T) {
+
+	fmt.Println("\n[TestOpenAPISchemaNames] start")
+
+	// same name as http.Cookie, in another package
+	type Cookie struct {
+		Token string `json:"token"`
+	}
+
+	router := New(RouterOptions{
+		RootPath:          "/test/openapi",
+		CloseConsolePrint: true,
+	})
+
+	router.EasyGET("/local", func(ctx *Context) *Cookie {
+		return &Cookie{}
+	})
+	router.EasyGET("/http", func(ctx *Context) *http.Cookie {
+		return &http.Cookie{}
+	})
+	router.EasyPOST("/local", func(ctx *Context, cookie Cookie) *Cookie {
+		return &cookie
+	})
+
+	doc := router.OpenAPI()
+
+	local := doc.Paths["/test/openapi/local"]["get"].Responses["200"].Content["application/json"].Schema.Ref
+	std := doc.Paths["/test/openapi/http"]["get"].Responses["200"].Content["application/json"].Schema.Ref
+	body := doc.Paths["/test/openapi/local"]["post"].RequestBody.Content["application/json"].Schema.Ref
+	fmt.Println("[TestOpenAPISchemaNames] refs ->", local, std, body)
+
+	if local == std {
+		t.Fatal("openapi same-named schemas collide:", local)
+	}
+	if body != local {
+		t.Fatal("openapi same type has different schemas:", body, local)
+	}
+	if std != "#/components/schemas/http.Cookie" {
+		t.Fatal("openapi schema name is not qualified by the package:", std)
+	}
+	if doc.Components.Schemas["Cookie"].Properties["token"] == nil || doc.Components.Schemas["http.Cookie"].Properties["Name"] == nil {
+		t.Fatal("openapi schemas error")
+	}
+
+	fmt.Println("\n[TestOpenAPISchemaNames] end")
+}
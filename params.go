@@ -2,6 +2,7 @@ package easierweb
 
 import (
 	"github.com/mitchellh/mapstructure"
+	"reflect"
 	"strconv"
 )
 
@@ -150,12 +151,189 @@ func (kv Params) ParseFloat64(key string) (float64, error) {
 }
 
 func (kv Params) Bind(obj any) error {
+	return bindValues(kv, obj)
+}
+
+// multi-value query/form/header parameters
+
+type MultiParams map[string][]string
+
+func (kv MultiParams) Set(key string, values ...string) MultiParams {
+	if kv == nil {
+		return kv
+	}
+	kv[key] = values
+	return kv
+}
+
+func (kv MultiParams) Add(key string, value string) MultiParams {
+	if kv == nil {
+		return kv
+	}
+	kv[key] = append(kv[key], value)
+	return kv
+}
+
+func (kv MultiParams) Get(key string) string {
+	if kv == nil || len(kv[key]) == 0 {
+		return ""
+	}
+	return kv[key][0]
+}
+
+func (kv MultiParams) GetAll(key string) []string {
+	if kv == nil {
+		return nil
+	}
+	return kv[key]
+}
+
+func (kv MultiParams) Del(key string) MultiParams {
+	if kv == nil {
+		return kv
+	}
+	delete(kv, key)
+	return kv
+}
+
+func (kv MultiParams) Has(key string) bool {
+	if kv == nil {
+		return false
+	}
+	_, has := kv[key]
+	return has
+}
+
+func (kv MultiParams) Keys() []string {
+	if kv == nil {
+		return nil
+	}
+	var ks = make([]string, 0, len(kv))
+	for k := range kv {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func (kv MultiParams) Strings(key string) []string {
+	return kv.GetAll(key)
+}
+
+func (kv MultiParams) Ints(key string) []int {
+	is, err := kv.ParseInts(key)
+	if err != nil {
+		panic(err)
+	}
+	return is
+}
+
+func (kv MultiParams) Int64s(key string) []int64 {
+	is, err := kv.ParseInt64s(key)
+	if err != nil {
+		panic(err)
+	}
+	return is
+}
+
+func (kv MultiParams) Float64s(key string) []float64 {
+	fs, err := kv.ParseFloat64s(key)
+	if err != nil {
+		panic(err)
+	}
+	return fs
+}
+
+func (kv MultiParams) ParseInts(key string) ([]int, error) {
+	values := kv.GetAll(key)
+	is := make([]int, 0, len(values))
+	for _, v := range values {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		is = append(is, i)
+	}
+	return is, nil
+}
+
+func (kv MultiParams) ParseInt64s(key string) ([]int64, error) {
+	values := kv.GetAll(key)
+	is := make([]int64, 0, len(values))
+	for _, v := range values {
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		is = append(is, i)
+	}
+	return is, nil
+}
+
+func (kv MultiParams) ParseFloat64s(key string) ([]float64, error) {
+	values := kv.GetAll(key)
+	fs := make([]float64, 0, len(values))
+	for _, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	return fs, nil
+}
+
+// Bind keys with one value are bound as single values, keys with multiple values are bound as slices
+func (kv MultiParams) Bind(obj any) error {
+	return bindValues(kv.values(nil), obj)
+}
+
+// merge single-value params with multi-value params, the single value params are the source of truth
+func (kv MultiParams) values(single Params) map[string]any {
+	m := make(map[string]any, len(kv))
+	if single == nil {
+		for k, v := range kv {
+			if len(v) == 1 {
+				m[k] = v[0]
+			} else if len(v) > 1 {
+				m[k] = v
+			}
+		}
+		return m
+	}
+	for k, v := range single {
+		if vs := kv[k]; len(vs) > 1 && vs[0] == v {
+			m[k] = vs
+		} else {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+func bindValues(values any, obj any) error {
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		WeaklyTypedInput: true,
+		DecodeHook:       firstValueHook,
 		Result:           obj,
 	})
 	if err != nil {
 		return err
 	}
-	return decoder.Decode(kv)
+	return decoder.Decode(values)
+}
+
+// firstValueHook the multiple values of a key are bound to the scalar fields as the first value, like the single-value params
+func firstValueHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	values, ok := data.([]string)
+	if !ok || len(values) == 0 {
+		return data, nil
+	}
+	for to.Kind() == reflect.Ptr {
+		to = to.Elem()
+	}
+	switch to.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Interface:
+		return data, nil
+	}
+	return values[0], nil
 }
@@ -19,6 +19,13 @@ type Err struct {
 
 func JSONErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 	return func(ctx *easierweb.Context, err any) {
+		if e, ok := err.(error); ok {
+			if code, body, ok := easierweb.ErrorResult(e); ok {
+				easierweb.LogServerError(ctx, code, e)
+				ctx.WriteJSON(code, body)
+				return
+			}
+		}
 		logError(ctx, err, opts...)
 		res := Err{}
 		if len(opts) > 0 && opts[0].ShowError {
@@ -32,6 +39,13 @@ func JSONErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 
 func YAMLErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 	return func(ctx *easierweb.Context, err any) {
+		if e, ok := err.(error); ok {
+			if code, body, ok := easierweb.ErrorResult(e); ok {
+				easierweb.LogServerError(ctx, code, e)
+				ctx.WriteYAML(code, body)
+				return
+			}
+		}
 		logError(ctx, err, opts...)
 		res := Err{}
 		if len(opts) > 0 && opts[0].ShowError {
@@ -45,6 +59,13 @@ func YAMLErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 
 func XMLErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 	return func(ctx *easierweb.Context, err any) {
+		if e, ok := err.(error); ok {
+			if code, body, ok := easierweb.ErrorResult(e); ok {
+				easierweb.LogServerError(ctx, code, e)
+				ctx.WriteXML(code, body)
+				return
+			}
+		}
 		logError(ctx, err, opts...)
 		res := Err{}
 		if len(opts) > 0 && opts[0].ShowError {
@@ -58,6 +79,13 @@ func XMLErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 
 func StringErrorHandle(opts ...ErrorHandleOptions) easierweb.ErrorHandle {
 	return func(ctx *easierweb.Context, err any) {
+		if e, ok := err.(error); ok {
+			if code, _, ok := easierweb.ErrorResult(e); ok {
+				easierweb.LogServerError(ctx, code, e)
+				ctx.WriteString(code, e.Error())
+				return
+			}
+		}
 		logError(ctx, err, opts...)
 		if len(opts) > 0 && opts[0].ShowError {
 			ctx.WriteString(http.StatusInternalServerError, fmt.Sprintf("%s", err))
@@ -0,0 +1,192 @@
+package plugins
+
+import (
+	"github.com/dpwgc/easierweb"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// content negotiation, choose the encoder by the Accept header and the decoder by the Content-Type header
+// easierweb.Codec implements both Encoder and Decoder
+
+type Encoder interface {
+	ContentType() string
+	Marshal(obj any) ([]byte, error)
+}
+
+type Decoder interface {
+	ContentType() string
+	Unmarshal(data []byte, obj any) error
+}
+
+// NegotiatingResponseHandle write the response in the format accepted by the client (Accept header with q-values)
+// the first encoder is used if the client accepts any format, respond 406 if no encoder is accepted
+// default encoders: the JSON, XML, YAML codecs registered on the router
+func NegotiatingResponseHandle(encoders ...Encoder) easierweb.ResponseHandle {
+	write := func(ctx *easierweb.Context, code int, obj any) {
+		encoders := encoders
+		if len(encoders) == 0 {
+			encoders = []Encoder{ctx.Codec(easierweb.CodecJSON), ctx.Codec(easierweb.CodecXML), ctx.Codec(easierweb.CodecYAML)}
+		}
+		ctx.AddHeader("Vary", "Accept")
+		encoder := negotiate(ctx.Request.Header.Get("Accept"), encoders)
+		if encoder == nil {
+			types := make([]string, 0, len(encoders))
+			for _, e := range encoders {
+				types = append(types, easierweb.MediaType(e.ContentType()))
+			}
+			ctx.WriteString(http.StatusNotAcceptable, "not acceptable, available types: "+strings.Join(types, ", "))
+			return
+		}
+		marshal, err := encoder.Marshal(obj)
+		if err != nil {
+			panic(err)
+		}
+		ctx.SetContentType(encoder.ContentType())
+		ctx.Write(code, marshal)
+	}
+	return func(ctx *easierweb.Context, result any, err error) {
+		if err != nil {
+			if code, body, ok := easierweb.ErrorResult(err); ok {
+				easierweb.LogServerError(ctx, code, err)
+				write(ctx, code, body)
+				return
+			}
+			if result != nil {
+				write(ctx, http.StatusBadRequest, result)
+				return
+			}
+			panic(err)
+		}
+		if result == nil {
+			ctx.NoContent(http.StatusNoContent)
+			return
+		}
+		write(ctx, http.StatusOK, result)
+	}
+}
+
+// NegotiatingRequestHandle parse the request body with the decoder that matches the Content-Type header
+// the aliases and the structured syntax suffixes are accepted (easierweb.MatchMediaType), e.g. application/problem+json, text/yaml
+// the first decoder is used if there is no Content-Type header, respond 415 if no decoder matches
+// default decoders: the JSON, XML, YAML codecs registered on the router
+func NegotiatingRequestHandle(decoders ...Decoder) easierweb.RequestHandle {
+	return func(ctx *easierweb.Context, reqObj any) error {
+		decoders := decoders
+		if len(decoders) == 0 {
+			decoders = []Decoder{ctx.Codec(easierweb.CodecJSON), ctx.Codec(easierweb.CodecXML), ctx.Codec(easierweb.CodecYAML)}
+		}
+		err := ctx.ParseBody()
+		if err != nil {
+			return err
+		}
+		if len(ctx.Form) > 0 {
+			err = ctx.BindForm(reqObj)
+			if err != nil {
+				return err
+			}
+		} else if len(ctx.Body) > 0 {
+			decoder := decoders[0]
+			if contentType := ctx.Request.Header.Get("Content-Type"); contentType != "" {
+				decoder = nil
+				for _, d := range decoders {
+					if easierweb.MatchMediaType(contentType, d.ContentType()) {
+						decoder = d
+						break
+					}
+				}
+			}
+			if decoder == nil {
+				return easierweb.NewBindError(easierweb.BindSourceBody, easierweb.ErrUnsupportedMediaType)
+			}
+			err = easierweb.NewBindError(easierweb.BindSourceBody, decoder.Unmarshal(ctx.Body, reqObj))
+			if err != nil {
+				return err
+			}
+		}
+		if len(ctx.Query) > 0 {
+			err = ctx.BindQuery(reqObj)
+			if err != nil {
+				return err
+			}
+		}
+		return ctx.BindParams(reqObj)
+	}
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+	// 0: */*, 1: type/*, 2: type/subtype
+	specificity int
+}
+
+// choose the encoder by the Accept header, the ranges are sorted by q-value and specificity
+func negotiate(accept string, encoders []Encoder) Encoder {
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0]
+	}
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		r := acceptRange{mediaType: easierweb.MediaType(mt), q: 1, specificity: 2}
+		if q, has := params["q"]; has {
+			r.q, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if mt == "*/*" {
+			r.specificity = 0
+		} else if strings.HasSuffix(mt, "/*") {
+			r.specificity = 1
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return ranges[i].specificity > ranges[j].specificity
+	})
+	for _, r := range ranges {
+		if r.q <= 0 {
+			break
+		}
+		for _, e := range encoders {
+			if mediaMatch(r, e.ContentType()) && !mediaRejected(ranges, easierweb.MediaType(e.ContentType())) {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// the wildcard ranges match the content type as is (text/* matches text/yaml), the others match the normalized media type (the aliases)
+func mediaMatch(r acceptRange, contentType string) bool {
+	switch r.specificity {
+	case 0:
+		return true
+	case 1:
+		mt, _, err := mime.ParseMediaType(contentType)
+		return err == nil && strings.HasPrefix(mt, strings.TrimSuffix(r.mediaType, "*"))
+	default:
+		return r.mediaType == easierweb.MediaType(contentType)
+	}
+}
+
+// the media type is explicitly rejected with q=0
+func mediaRejected(ranges []acceptRange, mt string) bool {
+	for _, r := range ranges {
+		if r.q <= 0 && r.specificity == 2 && r.mediaType == mt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"fmt"
+	"github.com/dpwgc/easierweb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// content negotiation test
+
+func TestNegotiation(t *testing.T) {
+
+	fmt.Println("\n[TestNegotiation] start")
+
+	requestHandle := NegotiatingRequestHandle()
+	responseHandle := NegotiatingResponseHandle()
+
+	cases := []struct {
+		contentType string
+		body        string
+		accept      string
+		code        int
+		resultType  string
+	}{
+		{"application/json", "{\"msg\":\"hello\"}", "", http.StatusOK, "application/json"},
+		{"application/xml", "<negotiationTestDTO><Msg>hello</Msg></negotiationTestDTO>", "application/xml", http.StatusOK, "application/xml"},
+		{"application/x-yaml", "msg: hello", "application/json;q=0.5, application/x-yaml", http.StatusOK, "application/x-yaml"},
+		{"application/json", "{\"msg\":\"hello\"}", "text/html;q=0.9, */*;q=0.1", http.StatusOK, "application/json"},
+		{"application/json", "{\"msg\":\"hello\"}", "application/*;q=0.5, application/json;q=0", http.StatusOK, "application/xml"},
+		{"application/json", "{\"msg\":\"hello\"}", "text/html", http.StatusNotAcceptable, "text/plain"},
+		{"application/vnd.api+json", "{\"msg\":\"hello\"}", "application/yaml", http.StatusOK, "application/x-yaml"},
+		{"text/yaml; charset=utf-8", "msg: hello", "text/xml", http.StatusOK, "application/xml"},
+		{"application/atom+xml", "<negotiationTestDTO><Msg>hello</Msg></negotiationTestDTO>", "", http.StatusOK, "application/json"},
+		{"text/csv", "msg,hello", "application/json", http.StatusUnsupportedMediaType, "application/json"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/test/negotiation/echo", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		rec := httptest.NewRecorder()
+		ctx := &easierweb.Context{
+			Request:        req,
+			ResponseWriter: rec,
+		}
+		dto := negotiationTestDTO{}
+		err := requestHandle(ctx, &dto)
+		if err != nil {
+			responseHandle(ctx, nil, err)
+		} else {
+			responseHandle(ctx, dto, nil)
+		}
+		res := rec.Result()
+		fmt.Printf("[TestNegotiation] content type: %s, accept: %s, response code: %v, response type: %s \n", c.contentType, c.accept, res.StatusCode, res.Header.Get("Content-Type"))
+		if res.StatusCode != c.code || !strings.HasPrefix(res.Header.Get("Content-Type"), c.resultType) {
+			t.Fatal("negotiation error:", c.contentType, c.accept)
+		}
+	}
+
+	fmt.Println("\n[TestNegotiation] end")
+}
+
+type negotiationTestDTO struct {
+	Msg string `json:"msg" xml:"Msg" yaml:"msg"`
+}
@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"encoding/xml"
+	"github.com/dpwgc/easierweb"
+	"net/http"
+	"strconv"
+)
+
+// RFC 7807 problem details, errors and panics are rendered as application/problem+json (or application/problem+xml)
+
+type ProblemOptions struct {
+	// render application/problem+xml and write the results in xml format
+	XML bool
+	// the problem type is TypeBaseURI + the HTTPError code (or the status code), default: about:blank
+	TypeBaseURI string
+	// render the unexpected error message in the detail member
+	ShowError bool
+	// output stack info in logs
+	OutputStack bool
+	// add extension members, e.g. trace id
+	Extensions func(ctx *easierweb.Context, err any) map[string]any
+}
+
+// ProblemErrorHandle render the panics as problem details, unexpected errors are logged and rendered as 500
+func ProblemErrorHandle(opts ...ProblemOptions) easierweb.ErrorHandle {
+	o := problemOptions(opts)
+	return func(ctx *easierweb.Context, err any) {
+		e, _ := err.(error)
+		if code, _, ok := easierweb.ErrorResult(e); ok {
+			easierweb.LogServerError(ctx, code, e)
+		} else {
+			logError(ctx, err, ErrorHandleOptions{OutputStack: o.OutputStack})
+		}
+		writeProblem(ctx, o, err)
+	}
+}
+
+// ProblemResponseHandle render the returned errors as problem details, the results are written in json (or xml) format
+// like the other response handles, the result of an unrecognized error is written with 400, otherwise the error is passed to the ErrorHandle
+func ProblemResponseHandle(opts ...ProblemOptions) easierweb.ResponseHandle {
+	o := problemOptions(opts)
+	write := func(ctx *easierweb.Context, code int, result any) {
+		if o.XML {
+			ctx.WriteXML(code, result)
+			return
+		}
+		ctx.WriteJSON(code, result)
+	}
+	return func(ctx *easierweb.Context, result any, err error) {
+		if err != nil {
+			if code, _, ok := easierweb.ErrorResult(err); ok {
+				easierweb.LogServerError(ctx, code, err)
+				writeProblem(ctx, o, err)
+				return
+			}
+			if result != nil {
+				write(ctx, http.StatusBadRequest, result)
+				return
+			}
+			panic(err)
+		}
+		if result == nil {
+			ctx.NoContent(http.StatusNoContent)
+			return
+		}
+		write(ctx, http.StatusOK, result)
+	}
+}
+
+func problemOptions(opts []ProblemOptions) ProblemOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return ProblemOptions{}
+}
+
+func writeProblem(ctx *easierweb.Context, o ProblemOptions, err any) {
+	problem := easierweb.NewProblem(ctx, err, o.ShowError)
+	if ctx.Route != "" {
+		problem.Extensions["route"] = ctx.Route
+	}
+	if o.TypeBaseURI != "" {
+		if code, has := problem.Extensions["code"].(string); has {
+			problem.Type = o.TypeBaseURI + code
+		} else {
+			problem.Type = o.TypeBaseURI + strconv.Itoa(problem.Status)
+		}
+	}
+	if o.Extensions != nil {
+		for k, v := range o.Extensions(ctx, err) {
+			problem.Extensions[k] = v
+		}
+	}
+	if !o.XML {
+		ctx.WriteProblem(problem)
+		return
+	}
+	marshal, e := xml.Marshal(problem)
+	if e != nil {
+		panic(e)
+	}
+	ctx.SetContentType(easierweb.ProblemXMLContentType)
+	ctx.Write(problem.Status, marshal)
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dpwgc/easierweb"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// problem details test
+
+func TestProblem(t *testing.T) {
+
+	fmt.Println("\n[TestProblem] start")
+
+	newRouter := func(opts ProblemOptions) *easierweb.Router {
+		router := easierweb.New(easierweb.RouterOptions{
+			RootPath:          "/test/problem",
+			ErrorHandle:       ProblemErrorHandle(opts),
+			ResponseHandle:    ProblemResponseHandle(opts),
+			Logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+			CloseConsolePrint: true,
+		})
+		router.EasyGET("/user/:id", func(ctx *easierweb.Context) (*negotiationTestDTO, error) {
+			return nil, easierweb.NotFound("user does not exist").WithCode("user-not-found")
+		})
+		router.EasyGET("/ok", func(ctx *easierweb.Context) (*negotiationTestDTO, error) {
+			return &negotiationTestDTO{Msg: "hello"}, nil
+		})
+		router.EasyGET("/partial", func(ctx *easierweb.Context) (*negotiationTestDTO, error) {
+			return &negotiationTestDTO{Msg: "partial"}, errors.New("partial failure")
+		})
+		router.GET("/panic", func(ctx *easierweb.Context) {
+			panic("boom")
+		})
+		return router
+	}
+
+	jsonRouter := newRouter(ProblemOptions{
+		TypeBaseURI: "https://example.com/problems/",
+		Extensions: func(ctx *easierweb.Context, err any) map[string]any {
+			return map[string]any{"traceId": "t1"}
+		},
+	})
+	xmlRouter := newRouter(ProblemOptions{XML: true, ShowError: true})
+
+	cases := []struct {
+		router      *easierweb.Router
+		uri         string
+		code        int
+		contentType string
+		contains    []string
+	}{
+		{jsonRouter, "/user/1", http.StatusNotFound, easierweb.ProblemJSONContentType, []string{
+			`"type":"https://example.com/problems/user-not-found"`, `"title":"Not Found"`, `"status":404`, `"detail":"user does not exist"`,
+			`"instance":"/test/problem/user/1"`, `"route":"/test/problem/user/:id"`, `"traceId":"t1"`, `"code":"user-not-found"`}},
+		{jsonRouter, "/panic", http.StatusInternalServerError, easierweb.ProblemJSONContentType, []string{
+			`"type":"https://example.com/problems/500"`, `"status":500`}},
+		{jsonRouter, "/ok", http.StatusOK, "application/json", []string{`"msg":"hello"`}},
+		{jsonRouter, "/partial", http.StatusBadRequest, "application/json", []string{`"msg":"partial"`}},
+		{xmlRouter, "/user/1", http.StatusNotFound, easierweb.ProblemXMLContentType, []string{
+			`<problem xmlns="urn:ietf:rfc:7807">`, `<type>about:blank</type>`, `<status>404</status>`, `<route>/test/problem/user/:id</route>`}},
+		{xmlRouter, "/panic", http.StatusInternalServerError, easierweb.ProblemXMLContentType, []string{`<detail>boom</detail>`}},
+		{xmlRouter, "/ok", http.StatusOK, "application/xml", []string{`<Msg>hello</Msg>`}},
+		{xmlRouter, "/partial", http.StatusBadRequest, "application/xml", []string{`<Msg>partial</Msg>`}},
+	}
+	for _, c := range cases {
+		res := httptest.NewRecorder()
+		c.router.ServeHTTP(res, httptest.NewRequest("GET", "/test/problem"+c.uri, nil))
+		fmt.Println("[TestProblem]", c.uri, "->", res.Code, res.Header().Get("Content-Type"), res.Body.String())
+		if res.Code != c.code || !strings.HasPrefix(res.Header().Get("Content-Type"), c.contentType) {
+			t.Errorf("%s unexpected response %d %s", c.uri, res.Code, res.Header().Get("Content-Type"))
+		}
+		for _, s := range c.contains {
+			if !strings.Contains(res.Body.String(), s) {
+				t.Errorf("%s body does not contain %s", c.uri, s)
+			}
+		}
+		if strings.HasPrefix(res.Header().Get("Content-Type"), "application/problem+json") && !json.Valid(res.Body.Bytes()) {
+			t.Errorf("%s invalid json body", c.uri)
+		}
+	}
+}
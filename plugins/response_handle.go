@@ -8,6 +8,11 @@ import (
 func JSONResponseHandle() easierweb.ResponseHandle {
 	return func(ctx *easierweb.Context, result any, err error) {
 		if err != nil {
+			if code, body, ok := easierweb.ErrorResult(err); ok {
+				easierweb.LogServerError(ctx, code, err)
+				ctx.WriteJSON(code, body)
+				return
+			}
 			if result != nil {
 				ctx.WriteJSON(http.StatusBadRequest, result)
 				return
@@ -25,6 +30,11 @@ func JSONResponseHandle() easierweb.ResponseHandle {
 func YAMLResponseHandle() easierweb.ResponseHandle {
 	return func(ctx *easierweb.Context, result any, err error) {
 		if err != nil {
+			if code, body, ok := easierweb.ErrorResult(err); ok {
+				easierweb.LogServerError(ctx, code, err)
+				ctx.WriteYAML(code, body)
+				return
+			}
 			if result != nil {
 				ctx.WriteYAML(http.StatusBadRequest, result)
 				return
@@ -42,6 +52,11 @@ func YAMLResponseHandle() easierweb.ResponseHandle {
 func XMLResponseHandle() easierweb.ResponseHandle {
 	return func(ctx *easierweb.Context, result any, err error) {
 		if err != nil {
+			if code, body, ok := easierweb.ErrorResult(err); ok {
+				easierweb.LogServerError(ctx, code, err)
+				ctx.WriteXML(code, body)
+				return
+			}
 			if result != nil {
 				ctx.WriteXML(http.StatusBadRequest, result)
 				return
@@ -59,6 +74,11 @@ func XMLResponseHandle() easierweb.ResponseHandle {
 func BytesResponseHandle() easierweb.ResponseHandle {
 	return func(ctx *easierweb.Context, result any, err error) {
 		if err != nil {
+			if code, _, ok := easierweb.ErrorResult(err); ok {
+				easierweb.LogServerError(ctx, code, err)
+				ctx.WriteString(code, err.Error())
+				return
+			}
 			if result != nil {
 				ctx.Write(http.StatusBadRequest, result.([]byte))
 				return
@@ -0,0 +1,208 @@
+package easierweb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// RouteOptions per-route options, zero values inherit the RouterOptions
+type RouteOptions struct {
+	// request body size limit, respond 413 if exceeded
+	MaxBodySize int64
+	// do not parse the request body before the handles run, it is parsed on the first binding or ctx.ParseBody()
+	LazyParse bool
+	// override the RouterOptions handles, e.g. an XML API group in a JSON router
+	RequestHandle  RequestHandle
+	ResponseHandle ResponseHandle
+	ErrorHandle    ErrorHandle
+	// interval of the sse keepalive comments, override RouterOptions.SSEKeepAlive, negative to disable
+	SSEKeepAlive time.Duration
+	// websocket options, override RouterOptions.WS
+	WS *WSOptions
+}
+
+// routeInfo the registration info of a route, the options can be changed after registration by With
+type routeInfo struct {
+	method      string
+	path        string
+	name        string
+	kind        string
+	handler     string
+	middlewares []Handle
+	// the easy handle plan, nil if the route is not registered by an easy or typed function
+	plan    *easyPlan
+	options RouteOptions
+}
+
+func (r *Router) addRoute(method, path, kind string, handle Handle, middlewares []Handle) *routeInfo {
+	info := &routeInfo{
+		method:      method,
+		path:        path,
+		kind:        kind,
+		middlewares: middlewares,
+	}
+	if handle != nil {
+		info.handler = funcName(handle)
+	}
+	r.routes = append(r.routes, info)
+	r.lastRoutes = []*routeInfo{info}
+	return info
+}
+
+// With set the options of the last registered route (all methods of the route registered by Any)
+func (r *Router) With(opts RouteOptions) *Router {
+	for _, info := range r.lastRoutes {
+		if opts.MaxBodySize != 0 {
+			info.options.MaxBodySize = opts.MaxBodySize
+		}
+		if opts.LazyParse {
+			info.options.LazyParse = true
+		}
+		if opts.RequestHandle != nil {
+			info.options.RequestHandle = opts.RequestHandle
+		}
+		if opts.ResponseHandle != nil {
+			info.options.ResponseHandle = opts.ResponseHandle
+		}
+		if opts.ErrorHandle != nil {
+			info.options.ErrorHandle = opts.ErrorHandle
+		}
+		if opts.SSEKeepAlive != 0 {
+			info.options.SSEKeepAlive = opts.SSEKeepAlive
+		}
+		if opts.WS != nil {
+			info.options.WS = opts.WS
+		}
+	}
+	return r
+}
+
+// Name name the last registered route (all methods of the route registered by Any), the name is used by URLFor
+// panic if the name is used by another path
+func (r *Router) Name(name string) *Router {
+	for _, info := range r.lastRoutes {
+		if named, has := r.namedRoutes[name]; has && named.path != info.path {
+			panic(fmt.Errorf("route name %s is already used by %s", name, named.path))
+		}
+		if r.namedRoutes == nil {
+			r.namedRoutes = make(map[string]*routeInfo)
+		}
+		info.name = name
+		r.namedRoutes[name] = info
+	}
+	return r
+}
+
+// URLFor build the path of the named route (with the RootPath and group prefixes), the path params are escaped
+// example: router.URLFor("user.show", easierweb.Params{"id": "42"}, url.Values{"tab": {"profile"}}) -> /api/user/42?tab=profile
+func (r *Router) URLFor(name string, params Params, query ...url.Values) (string, error) {
+	info, has := r.namedRoutes[name]
+	if !has {
+		return "", fmt.Errorf("route %s does not exist", name)
+	}
+	segments := strings.Split(info.path, "/")
+	for i, s := range segments {
+		if len(s) < 2 || (s[0] != ':' && s[0] != '*') {
+			continue
+		}
+		value, has := params[s[1:]]
+		if !has {
+			return "", fmt.Errorf("route %s path param %s is missing", name, s[1:])
+		}
+		if s[0] == ':' {
+			segments[i] = url.PathEscape(value)
+			continue
+		}
+		// the catch-all param keeps the slashes
+		parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for j, p := range parts {
+			parts[j] = url.PathEscape(p)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+	path := strings.Join(segments, "/")
+	values := url.Values{}
+	for _, q := range query {
+		for k, v := range q {
+			values[k] = append(values[k], v...)
+		}
+	}
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	return path, nil
+}
+
+const (
+	RouteKindBasic  = "basic"
+	RouteKindEasy   = "easy"
+	RouteKindWS     = "websocket"
+	RouteKindSSE    = "sse"
+	RouteKindStatic = "static"
+	RouteKindMount  = "mount"
+)
+
+// Route the registered route, returned by router.Routes
+type Route struct {
+	Method string `json:"method" yaml:"method"`
+	// full path with the RootPath and group prefixes
+	Path    string `json:"path" yaml:"path"`
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Handler string `json:"handler,omitempty" yaml:"handler,omitempty"`
+	// request and response types of the easy handles
+	RequestType  string `json:"requestType,omitempty" yaml:"requestType,omitempty"`
+	ResponseType string `json:"responseType,omitempty" yaml:"responseType,omitempty"`
+	// global middlewares and route middlewares in execution order
+	Middlewares []string `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+}
+
+// Routes list the registered routes in registration order
+func (r *Router) Routes() []Route {
+	routes := make([]Route, 0, len(r.routes))
+	for _, info := range r.routes {
+		route := Route{
+			Method:  info.method,
+			Path:    info.path,
+			Name:    info.name,
+			Kind:    info.kind,
+			Handler: info.handler,
+		}
+		if info.plan != nil {
+			if info.plan.reqType != nil {
+				route.RequestType = info.plan.reqType.String()
+			}
+			if info.plan.resType != nil {
+				route.ResponseType = info.plan.resType.String()
+			}
+		}
+		for _, m := range r.middlewares {
+			route.Middlewares = append(route.Middlewares, funcName(m))
+		}
+		for _, m := range info.middlewares {
+			route.Middlewares = append(route.Middlewares, funcName(m))
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// RoutesRoute serve the registered routes in JSON format, it is intended for debugging
+func (r *Router) RoutesRoute(path string, middlewares ...Handle) *Router {
+	return r.GET(path, func(ctx *Context) {
+		ctx.WriteJSON(http.StatusOK, r.Routes())
+	}, middlewares...)
+}
+
+func (r *Router) consoleRoutesPrint() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, route := range r.Routes() {
+		_, _ = fmt.Fprintf(w, " %s\t%s\t%s\t%s\n", route.Method, route.Path, route.Kind, route.Handler)
+	}
+	_ = w.Flush()
+}
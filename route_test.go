@@ -0,0 +1,168 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// route introspection test
+
+func TestRoutes(t *testing.T) {
+
+	fmt.Println("\n[TestRoutes] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/routes",
+		CloseConsolePrint: true,
+	}).Use(routerTestMiddleware)
+
+	router.GET("/get/:id", routerTestAPI, groupTestMiddleware)
+	router.EasyPOST("/easy/post", routerTestEasySaveAPI)
+	router.Group("/group").EasyGET("/easy/get/:id", routerTestEasyQueryAPI)
+	router.WS("/ws", routerTestWebsocketConnect)
+	router.SSE("/sse", func(ctx *Context) {})
+	router.Static("/static/*filepath", ".")
+	router.Mount("/mount", http.NewServeMux())
+	router.RoutesRoute("/debug/routes")
+
+	routes := router.Routes()
+	for _, route := range routes {
+		fmt.Println("[TestRoutes]", route.Method, route.Path, route.Kind, route.Handler, route.RequestType, route.ResponseType, route.Middlewares)
+	}
+
+	expected := []Route{
+		{Method: "GET", Path: "/test/routes/get/:id", Kind: RouteKindBasic, Handler: "routerTestAPI"},
+		{Method: "POST", Path: "/test/routes/easy/post", Kind: RouteKindEasy, Handler: "routerTestEasySaveAPI", RequestType: "easierweb.routerTestDTO", ResponseType: "*easierweb.routerTestDTO"},
+		{Method: "GET", Path: "/test/routes/group/easy/get/:id", Kind: RouteKindEasy, Handler: "routerTestEasyQueryAPI", RequestType: "easierweb.routerTestDTO", ResponseType: "*easierweb.routerTestDTO"},
+		{Method: "GET", Path: "/test/routes/ws", Kind: RouteKindWS, Handler: "routerTestWebsocketConnect"},
+		{Method: "GET", Path: "/test/routes/sse", Kind: RouteKindSSE},
+		{Method: "GET", Path: "/test/routes/static/*filepath", Kind: RouteKindStatic, Handler: "http.Dir"},
+	}
+	for i, e := range expected {
+		route := routes[i]
+		if route.Method != e.Method || route.Path != e.Path || route.Kind != e.Kind || !strings.HasSuffix(route.Handler, e.Handler) ||
+			route.RequestType != e.RequestType || route.ResponseType != e.ResponseType {
+			t.Errorf("unexpected route: %+v, expected %+v", route, e)
+		}
+	}
+	if len(routes[0].Middlewares) != 2 || !strings.HasSuffix(routes[0].Middlewares[1], "groupTestMiddleware") {
+		t.Errorf("unexpected route middlewares: %v", routes[0].Middlewares)
+	}
+	// mount routes are registered for all methods
+	if mounts := routes[len(expected) : len(expected)+len(methodNames)]; mounts[0].Kind != RouteKindMount || mounts[0].Handler != "*http.ServeMux" {
+		t.Errorf("unexpected mount route: %+v", mounts[0])
+	}
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest("GET", "/test/routes/debug/routes", nil))
+	var served []Route
+	err := json.Unmarshal(res.Body.Bytes(), &served)
+	if err != nil || len(served) != len(routes) {
+		t.Errorf("unexpected debug routes response: %s %v", res.Body.String(), err)
+	}
+}
+
+// named routes test
+
+func TestURLFor(t *testing.T) {
+
+	fmt.Println("\n[TestURLFor] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/api",
+		CloseConsolePrint: true,
+	})
+	router.GET("/user/:id", routerTestAPI).Name("user.show")
+	router.Group("/files").GET("/*path", routerTestAPI).Name("file.show")
+	router.Any("/any/:id", routerTestAPI).Name("any")
+
+	cases := []struct {
+		name   string
+		params Params
+		query  url.Values
+		url    string
+		ok     bool
+	}{
+		{"user.show", Params{"id": "42"}, nil, "/api/user/42", true},
+		{"user.show", Params{"id": "a b/c"}, url.Values{"tab": {"profile"}}, "/api/user/a%20b%2Fc?tab=profile", true},
+		{"file.show", Params{"path": "/docs/read me.md"}, nil, "/api/files/docs/read%20me.md", true},
+		{"any", Params{"id": "1"}, nil, "/api/any/1", true},
+		{"user.show", Params{}, nil, "", false},
+		{"missing", nil, nil, "", false},
+	}
+	for _, c := range cases {
+		u, err := router.URLFor(c.name, c.params, c.query)
+		fmt.Println("[TestURLFor]", c.name, "->", u, err)
+		if u != c.url || (err == nil) != c.ok {
+			t.Errorf("URLFor(%s) = %s, %v, expected %s", c.name, u, err, c.url)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("duplicate route name does not panic")
+		}
+	}()
+	router.GET("/other", routerTestAPI).Name("user.show")
+}
+
+// route handles override test
+
+func TestRouteHandles(t *testing.T) {
+
+	fmt.Println("\n[TestRouteHandles] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/handles",
+		CloseConsolePrint: true,
+	})
+
+	xmlResponse := func(ctx *Context, result any, err error) {
+		if err != nil {
+			ctx.WriteXML(http.StatusBadRequest, map[string]string{"msg": err.Error()})
+			return
+		}
+		ctx.WriteXML(http.StatusOK, result)
+	}
+	xmlRequest := func(ctx *Context, reqObj any) error {
+		return ctx.BindXML(reqObj)
+	}
+	stringError := func(ctx *Context, err any) {
+		ctx.WriteString(http.StatusTeapot, fmt.Sprint(err))
+	}
+
+	router.EasyPOST("/json", routerTestEasySaveAPI)
+	partner := router.Group("/partner").Options(RouteOptions{
+		RequestHandle:  xmlRequest,
+		ResponseHandle: xmlResponse,
+	})
+	partner.EasyPOST("/xml", routerTestEasySaveAPI)
+	partner.GET("/error", routerTestErrorAPI).With(RouteOptions{ErrorHandle: stringError})
+	router.GET("/error", routerTestErrorAPI)
+
+	cases := []struct {
+		method      string
+		uri         string
+		body        string
+		code        int
+		contentType string
+	}{
+		{"POST", "/json", "{\"int64\":3}", http.StatusOK, "application/json; charset=utf-8"},
+		{"POST", "/partner/xml", "<routerTestDTO><Int64>3</Int64></routerTestDTO>", http.StatusOK, "application/xml; charset=utf-8"},
+		{"GET", "/partner/error", "", http.StatusTeapot, "text/plain; charset=utf-8"},
+		{"GET", "/error", "", http.StatusInternalServerError, "text/plain; charset=utf-8"},
+	}
+	for _, c := range cases {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(c.method, "/test/handles"+c.uri, strings.NewReader(c.body)))
+		fmt.Println("[TestRouteHandles]", c.uri, "->", res.Code, res.Header().Get("Content-Type"), res.Body.String())
+		if res.Code != c.code || res.Header().Get("Content-Type") != c.contentType {
+			t.Errorf("%s unexpected response %d %s", c.uri, res.Code, res.Header().Get("Content-Type"))
+		}
+	}
+}
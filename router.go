@@ -8,15 +8,30 @@ import (
 	"golang.org/x/net/websocket"
 	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type RouterOptions struct {
 	RootPath               string
 	MultipartFormMaxMemory int64
+	MaxBodySize            int64
+	LazyParse              bool
 	ErrorHandle            ErrorHandle
 	RequestHandle          RequestHandle
 	ResponseHandle         ResponseHandle
+	NotFoundHandle         Handle
+	MethodNotAllowedHandle Handle
+	OptionsHandle          Handle
+	Validator              Validator
+	ProblemDetails         bool
+	Codecs                 map[string]Codec
+	ShutdownTimeout        time.Duration
+	ShutdownHookTimeout    time.Duration
+	SSEKeepAlive           time.Duration
+	WS                     WSOptions
 	Logger                 *slog.Logger
 	CloseConsolePrint      bool
 }
@@ -24,24 +39,50 @@ type RouterOptions struct {
 type Router struct {
 	rootPath               string
 	multipartFormMaxMemory int64
+	maxBodySize            int64
+	lazyParse              bool
 	router                 *httprouter.Router
 	server                 *http.Server
 	middlewares            []Handle
 	errorHandle            ErrorHandle
 	requestHandle          RequestHandle
 	responseHandle         ResponseHandle
+	notFoundHandle         Handle
+	methodNotAllowedHandle Handle
+	optionsHandle          Handle
+	validator              Validator
+	codecs                 atomic.Pointer[map[string]Codec]
+	codecMutex             sync.Mutex
+	shutdownTimeout        time.Duration
+	shutdownHookTimeout    time.Duration
+	sseKeepAlive           time.Duration
+	ws                     WSOptions
+	startHooks             []Hook
+	shutdownHooks          []Hook
+	conns                  map[*Context]context.CancelFunc
+	connWaitGroup          sync.WaitGroup
+	shuttingDown           bool
+	lifecycleMutex         sync.Mutex
 	logger                 *slog.Logger
 	contextPool            *sync.Pool
 	closeConsolePrint      bool
+	easyAPIs               []easyAPI
+	routes                 []*routeInfo
+	lastRoutes             []*routeInfo
+	namedRoutes            map[string]*routeInfo
 }
 
 func New(opts ...RouterOptions) *Router {
 	r := &Router{
 		multipartFormMaxMemory: 32 << 20,
 		router:                 httprouter.New(),
-		errorHandle:            defaultErrorHandle(),
 		requestHandle:          defaultRequestHandle(),
-		responseHandle:         defaultResponseHandle(),
+		notFoundHandle:         defaultNotFoundHandle,
+		methodNotAllowedHandle: defaultMethodNotAllowedHandle,
+		optionsHandle:          defaultOptionsHandle,
+		shutdownTimeout:        10 * time.Second,
+		shutdownHookTimeout:    10 * time.Second,
+		sseKeepAlive:           15 * time.Second,
 		logger:                 slog.Default(),
 		contextPool: &sync.Pool{
 			New: func() any {
@@ -49,6 +90,8 @@ func New(opts ...RouterOptions) *Router {
 			},
 		},
 	}
+	codecs := defaultCodecs()
+	problemDetails := false
 	for _, v := range opts {
 		if v.RootPath != "" {
 			r.rootPath = v.RootPath
@@ -56,6 +99,10 @@ func New(opts ...RouterOptions) *Router {
 		if v.MultipartFormMaxMemory > 0 {
 			r.multipartFormMaxMemory = v.MultipartFormMaxMemory
 		}
+		if v.MaxBodySize > 0 {
+			r.maxBodySize = v.MaxBodySize
+		}
+		r.lazyParse = v.LazyParse
 		if v.ErrorHandle != nil {
 			r.errorHandle = v.ErrorHandle
 		}
@@ -65,11 +112,45 @@ func New(opts ...RouterOptions) *Router {
 		if v.ResponseHandle != nil {
 			r.responseHandle = v.ResponseHandle
 		}
+		if v.NotFoundHandle != nil {
+			r.notFoundHandle = v.NotFoundHandle
+		}
+		if v.MethodNotAllowedHandle != nil {
+			r.methodNotAllowedHandle = v.MethodNotAllowedHandle
+		}
+		if v.OptionsHandle != nil {
+			r.optionsHandle = v.OptionsHandle
+		}
+		if v.Validator != nil {
+			r.validator = v.Validator
+		}
+		for name, codec := range v.Codecs {
+			codecs[name] = codec
+		}
+		if v.ShutdownTimeout > 0 {
+			r.shutdownTimeout = v.ShutdownTimeout
+		}
+		if v.ShutdownHookTimeout > 0 {
+			r.shutdownHookTimeout = v.ShutdownHookTimeout
+		}
+		if v.SSEKeepAlive != 0 {
+			r.sseKeepAlive = v.SSEKeepAlive
+		}
+		r.ws = v.WS
 		if v.Logger != nil {
 			r.logger = v.Logger
 		}
 		r.closeConsolePrint = v.CloseConsolePrint
+		problemDetails = v.ProblemDetails
 	}
+	r.codecs.Store(&codecs)
+	if r.errorHandle == nil {
+		r.errorHandle = defaultErrorHandle(problemDetails)
+	}
+	if r.responseHandle == nil {
+		r.responseHandle = defaultResponseHandle(problemDetails)
+	}
+	r.setFallbackHandles()
 	return r
 }
 
@@ -114,11 +195,51 @@ func (r *Router) EasyDELETE(path string, easyHandle any, middlewares ...Handle)
 }
 
 func (r *Router) EasyAPI(method, path string, easyHandle any, middlewares ...Handle) *Router {
-	return r.API(method, path, r.easyHandle(easyHandle), middlewares...)
+	r.planAPI(method, path, newEasyPlan(easyHandle), middlewares...)
+	return r
 }
 
 func (r *Router) EasyAny(path string, easyHandle any, middlewares ...Handle) *Router {
-	return r.Any(path, r.easyHandle(easyHandle), middlewares...)
+	plan := newEasyPlan(easyHandle)
+	r.checkPlan(plan)
+	for _, method := range methodNames {
+		r.addEasyAPI(method, r.rootPath+path, plan)
+	}
+	r.Any(path, r.easyHandle(plan), middlewares...)
+	r.setLastRoutesPlan(plan)
+	return r
+}
+
+func (r *Router) planAPI(method, path string, plan *easyPlan, middlewares ...Handle) {
+	r.checkPlan(plan)
+	r.addEasyAPI(method, r.rootPath+path, plan)
+	r.API(method, path, r.easyHandle(plan), middlewares...)
+	r.setLastRoutesPlan(plan)
+}
+
+// checkPlan check the request type of the handle at registration, panic if the tags (default, validate) are invalid
+func (r *Router) checkPlan(plan *easyPlan) {
+	if plan.reqType == nil {
+		return
+	}
+	err := checkDefaults(plan.reqType)
+	if err != nil {
+		panic(fmt.Errorf("handle %s request type: %s", plan.funcName, err))
+	}
+	if checker, ok := r.validator.(typeChecker); ok {
+		err = checker.Check(plan.reqType)
+		if err != nil {
+			panic(fmt.Errorf("handle %s request type: %s", plan.funcName, err))
+		}
+	}
+}
+
+func (r *Router) setLastRoutesPlan(plan *easyPlan) {
+	for _, info := range r.lastRoutes {
+		info.kind = RouteKindEasy
+		info.handler = plan.funcName
+		info.plan = plan
+	}
 }
 
 // basic usage function
@@ -154,40 +275,38 @@ func (r *Router) DELETE(path string, handle Handle, middlewares ...Handle) *Rout
 var methodNames = []string{MethodGET, MethodHEAD, MethodOPTIONS, MethodPOST, MethodPUT, MethodPATCH, MethodDELETE}
 
 func (r *Router) Any(path string, handle Handle, middlewares ...Handle) *Router {
+	var infos []*routeInfo
 	for _, method := range methodNames {
 		r.API(method, path, handle, middlewares...)
+		infos = append(infos, r.lastRoutes...)
 	}
+	r.lastRoutes = infos
 	return r
 }
 
 func (r *Router) API(method, path string, handle Handle, middlewares ...Handle) *Router {
-	route := r.rootPath + path
-	r.router.Handle(method, route, func(res http.ResponseWriter, req *http.Request, par httprouter.Params) {
-		r.handle(route, handle, res, req, par, nil, false, middlewares...)
+	info := r.addRoute(method, r.rootPath+path, RouteKindBasic, handle, middlewares)
+	r.router.Handle(method, info.path, func(res http.ResponseWriter, req *http.Request, par httprouter.Params) {
+		r.handle(info, handle, res, req, par, nil, false, middlewares...)
 	})
 	return r
 }
 
 func (r *Router) WS(path string, handle Handle, middlewares ...Handle) *Router {
-	route := r.rootPath + path
-	r.router.GET(route, func(res http.ResponseWriter, req *http.Request, par httprouter.Params) {
-		websocket.Server{
-			Handler: func(ws *websocket.Conn) {
-				r.handle(route, handle, res, req, par, ws, false, middlewares...)
-			},
-			Handshake: func(config *websocket.Config, req *http.Request) error {
-				// 解决跨域
-				return nil
-			},
-		}.ServeHTTP(res, req)
+	info := r.addRoute(MethodGET, r.rootPath+path, RouteKindWS, handle, middlewares)
+	r.router.GET(info.path, func(res http.ResponseWriter, req *http.Request, par httprouter.Params) {
+		res, req = newHeartbeat(r.wsOptions(info)).withHeartbeat(res, req)
+		r.wsServer(info, func(ws *websocket.Conn) {
+			r.handle(info, handle, res, req, par, ws, false, middlewares...)
+		}).ServeHTTP(res, req)
 	})
 	return r
 }
 
 func (r *Router) SSE(path string, handle Handle, middlewares ...Handle) *Router {
-	route := r.rootPath + path
-	r.router.GET(route, func(res http.ResponseWriter, req *http.Request, par httprouter.Params) {
-		r.handle(route, handle, res, req, par, nil, true, middlewares...)
+	info := r.addRoute(MethodGET, r.rootPath+path, RouteKindSSE, handle, middlewares)
+	r.router.GET(info.path, func(res http.ResponseWriter, req *http.Request, par httprouter.Params) {
+		r.handle(info, handle, res, req, par, nil, true, middlewares...)
 	})
 	return r
 }
@@ -197,11 +316,27 @@ func (r *Router) Static(path, dir string) *Router {
 }
 
 func (r *Router) StaticFS(path string, fs http.FileSystem) *Router {
-	route := r.rootPath + path
-	r.router.ServeFiles(route, fs)
+	info := r.addRoute(MethodGET, r.rootPath+path, RouteKindStatic, nil, nil)
+	info.handler = fmt.Sprintf("%T", fs)
+	r.router.ServeFiles(info.path, fs)
 	return r
 }
 
+// Mount mount a http.Handler under the prefix (all methods), the prefix is stripped from the request path before the handler is called
+// the router and route middlewares are executed before the handler, and the request body is not parsed
+func (r *Router) Mount(prefix string, handler http.Handler, middlewares ...Handle) *Router {
+	prefix = strings.TrimSuffix(prefix, "/")
+	stripped := http.StripPrefix(r.rootPath+prefix, handler)
+	r.Any(prefix+"/*mountPath", func(ctx *Context) {
+		stripped.ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	}, middlewares...)
+	for _, info := range r.lastRoutes {
+		info.kind = RouteKindMount
+		info.handler = fmt.Sprintf("%T", handler)
+	}
+	return r.With(RouteOptions{LazyParse: true})
+}
+
 func (r *Router) Use(middlewares ...Handle) *Router {
 	r.middlewares = append(r.middlewares, middlewares...)
 	return r
@@ -220,22 +355,34 @@ func (r *Router) RunTLS(addr string, certFile string, keyFile string, tlsConfig
 	}, certFile, keyFile)
 }
 
+// ServeHTTP the router implements http.Handler, it can be mounted under another mux, wrapped by net/http middlewares or tested with httptest
+func (r *Router) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	r.router.ServeHTTP(res, req)
+}
+
 func (r *Router) Serve(server *http.Server) error {
-	r.server = server
-	r.server.Handler = r.router
-	r.consoleStartPrint(r.server.Addr)
-	return r.server.ListenAndServe()
+	server.Handler = r
+	err := r.runStartHooks(server)
+	if err != nil {
+		return err
+	}
+	r.consoleStartPrint(server.Addr)
+	return server.ListenAndServe()
 }
 
 func (r *Router) ServeTLS(server *http.Server, certFile string, keyFile string) error {
-	r.server = server
-	r.server.Handler = r.router
-	r.consoleStartPrint(r.server.Addr)
-	return r.server.ListenAndServeTLS(certFile, keyFile)
+	server.Handler = r
+	err := r.runStartHooks(server)
+	if err != nil {
+		return err
+	}
+	r.consoleStartPrint(server.Addr)
+	return server.ListenAndServeTLS(certFile, keyFile)
 }
 
+// Close shut down the server gracefully, see Shutdown
 func (r *Router) Close() error {
-	return r.server.Shutdown(context.Background())
+	return r.Shutdown(context.Background())
 }
 
 func (r *Router) consoleStartPrint(addr string) {
@@ -243,5 +390,6 @@ func (r *Router) consoleStartPrint(addr string) {
 		return
 	}
 	fmt.Println("  ______          _        __          __  _     \n |  ____|        (_)       \\ \\        / / | |    \n | |__   __ _ ___ _  ___ _ _\\ \\  /\\  / /__| |__  \n |  __| / _` / __| |/ _ \\ '__\\ \\/  \\/ / _ \\ '_ \\ \n | |___| (_| \\__ \\ |  __/ |   \\  /\\  /  __/ |_) |\n |______\\__,_|___/_|\\___|_|    \\/  \\/ \\___|_.__/")
+	r.consoleRoutesPrint()
 	fmt.Printf("\033[1;32;40m%s\033[0m\n", fmt.Sprintf(" >>> server runs on [%s] ", addr))
 }
@@ -7,6 +7,8 @@ import (
 	"golang.org/x/net/websocket"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,29 +48,62 @@ func TestRouter(t *testing.T) {
 	router.EasyGET("/easy/error", routerTestErrorAPI)
 	router.EasyGET("/easy/error/return", routerTestErrorReturnAPI)
 
-	go func() {
-		time.Sleep(3 * time.Second)
-		routerTestHttpSendExecute()
-		fmt.Println()
-		time.Sleep(1 * time.Second)
-		routerTestWebsocketClientExecute()
-		time.Sleep(2 * time.Second)
-		fmt.Println("\n[TestRouter](go func) close router")
-		err := router.Close()
-		if err != nil {
-			panic(err)
-		}
-	}()
+	// http requests are served without opening a port
+	routerTestHttpSendExecute(t, router)
+	fmt.Println()
 
-	// started on port 80
-	err := router.Run(":80")
-	if err != nil && err.Error() != "http: Server closed" {
-		panic(err)
-	}
+	// websocket connections need a real connection, use a test server on a random local port
+	server := httptest.NewServer(router)
+	defer server.Close()
+	routerTestWebsocketClientExecute(t, server.URL)
 
 	fmt.Println("\n[TestRouter] end")
 }
 
+// mount and http.Handler test
+func TestMount(t *testing.T) {
+
+	fmt.Println("\n[TestMount] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/mount",
+		CloseConsolePrint: true,
+	}).Use(func(ctx *Context) {
+		ctx.SetHeader("X-Middleware", "easierweb")
+		ctx.Next()
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/legacy/hello", func(res http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		_, _ = res.Write([]byte(req.Method + " " + req.URL.Path + " " + string(body)))
+	})
+	router.Mount("/v1", mux)
+
+	// wrap the router with a net/http middleware
+	handler := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("X-Wrapped", "net/http")
+		router.ServeHTTP(res, req)
+	})
+
+	code, result := requestDo(handler, "POST", "/test/mount/v1/legacy/hello", []byte("hi"))
+	fmt.Println("[TestMount] response:", code, string(result))
+	if code != http.StatusOK || string(result) != "POST /legacy/hello hi" {
+		t.Errorf("unexpected mount response: %d %s", code, result)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/test/mount/v1/legacy/hello", nil))
+	if recorder.Header().Get("X-Middleware") != "easierweb" || recorder.Header().Get("X-Wrapped") != "net/http" {
+		t.Errorf("middlewares are not executed: %v", recorder.Header())
+	}
+
+	code, _ = requestDo(router, "GET", "/test/mount/v1/missing", nil)
+	if code != http.StatusNotFound {
+		t.Errorf("status code %d, expected 404", code)
+	}
+}
+
 // middleware
 func routerTestMiddleware(ctx *Context) {
 	fmt.Println("[TestRouter](routerTestMiddleware) route ->", ctx.Route)
@@ -176,88 +211,89 @@ func routerTestWebsocketConnect(ctx *Context) {
 	return
 }
 
-func routerTestWebsocketClientExecute() {
-	origin := "http://localhost/"
-	url := "ws://localhost/test/router/ws"
+func routerTestWebsocketClientExecute(t *testing.T, serverURL string) {
+	origin := serverURL + "/"
+	url := "ws" + strings.TrimPrefix(serverURL, "http") + "/test/router/ws"
 	ws, err := websocket.Dial(url, "", origin)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
+	defer ws.Close()
 	_, err = ws.Write([]byte("test msg"))
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 	var buf = make([]byte, 100)
 	n, err := ws.Read(buf)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 	fmt.Println("[TestRouter](routerTestWebsocketClientExecute) client read websocket msg ->", string(buf[:n]))
+	if string(buf[:n]) != "test msg" {
+		t.Errorf("unexpected websocket msg: %s", buf[:n])
+	}
 }
 
-func routerTestHttpSendExecute() {
+func routerTestHttpSendExecute(t *testing.T, router *Router) {
 
 	body := "{\"int\":1,\"int32\":2,\"int64\":3,\"string\":\"test\",\"float32\":1.1,\"float64\":2.2}"
 
-	routerTestHttpClient("HEAD", "/head/123", "")
-	routerTestHttpClient("HEAD", "/easy/head/123", "")
+	cases := []struct {
+		method string
+		uri    string
+		body   string
+		code   int
+	}{
+		{"HEAD", "/head/123", "", http.StatusNoContent},
+		{"HEAD", "/easy/head/123", "", http.StatusNoContent},
 
-	routerTestHttpClient("OPTIONS", "/options/123", "")
-	routerTestHttpClient("OPTIONS", "/easy/options/123", "")
+		{"OPTIONS", "/options/123", "", http.StatusOK},
+		{"OPTIONS", "/easy/options/123", "", http.StatusOK},
 
-	routerTestHttpClient("GET", "/get/123?int=1&int32=2&int64=3&string=test&float32=1.1&float64=2.2", "")
-	routerTestHttpClient("GET", "/easy/get/123?int=1&int32=2&int64=3&string=test&float32=1.1&float64=2.2", "")
+		{"GET", "/get/123?int=1&int32=2&int64=3&string=test&float32=1.1&float64=2.2", "", http.StatusOK},
+		{"GET", "/easy/get/123?int=1&int32=2&int64=3&string=test&float32=1.1&float64=2.2", "", http.StatusOK},
 
-	routerTestHttpClient("POST", "/post", body)
-	routerTestHttpClient("POST", "/easy/post", body)
+		{"POST", "/post", body, http.StatusOK},
+		{"POST", "/easy/post", body, http.StatusOK},
 
-	routerTestHttpClient("PUT", "/put/123", body)
-	routerTestHttpClient("PUT", "/easy/put/123", body)
+		{"PUT", "/put/123", body, http.StatusOK},
+		{"PUT", "/easy/put/123", body, http.StatusOK},
 
-	routerTestHttpClient("PATCH", "/patch/123", body)
-	routerTestHttpClient("PATCH", "/easy/patch/123", body)
+		{"PATCH", "/patch/123", body, http.StatusOK},
+		{"PATCH", "/easy/patch/123", body, http.StatusOK},
 
-	routerTestHttpClient("DELETE", "/delete/123", "")
-	routerTestHttpClient("DELETE", "/easy/delete/123", "")
+		{"DELETE", "/delete/123", "", http.StatusOK},
+		{"DELETE", "/easy/delete/123", "", http.StatusNoContent},
 
-	routerTestHttpClient("GET", "/error", "")
-	routerTestHttpClient("GET", "/easy/error", "")
-	routerTestHttpClient("GET", "/easy/error/return", "")
+		{"GET", "/error", "", http.StatusInternalServerError},
+		{"GET", "/easy/error", "", http.StatusInternalServerError},
+		{"GET", "/easy/error/return", "", http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		code := routerTestHttpClient(router, c.method, c.uri, c.body)
+		if code != c.code {
+			t.Errorf("%s %s status code %d, expected %d", c.method, c.uri, code, c.code)
+		}
+	}
 }
 
-func routerTestHttpClient(method, uri, body string) {
+func routerTestHttpClient(router *Router, method, uri, body string) int {
 	fmt.Printf("\n[TestRouter](routerTestHttpClient) request method: %s, uri: %s, body: %s \n", method, uri, body)
-	code, result, err := requestDo(method, "http://localhost/test/router"+uri, []byte(body))
-	if err != nil {
-		panic(err)
-	}
+	code, result := requestDo(router, method, "/test/router"+uri, []byte(body))
 	fmt.Printf("[TestRouter](routerTestHttpClient) response code: %v, data -> %s \n", code, string(result))
+	return code
 }
 
-func requestDo(method, url string, body []byte, header ...map[string]string) (int, Data, error) {
-	request, err := http.NewRequest(method, url, bytes.NewReader(body))
-	if err != nil {
-		return 0, nil, err
-	}
-	if len(header) > 0 {
-		for _, h := range header {
-			for k, v := range h {
-				request.Header.Set(k, v)
-			}
+func requestDo(handler http.Handler, method, uri string, body []byte, header ...map[string]string) (int, Data) {
+	request := httptest.NewRequest(method, uri, bytes.NewReader(body))
+	for _, h := range header {
+		for k, v := range h {
+			request.Header.Set(k, v)
 		}
 	}
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return 0, nil, err
-	}
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(response.Body)
-	result, err := io.ReadAll(response.Body)
-	if err != nil {
-		return 0, nil, err
-	}
-	return response.StatusCode, result, nil
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.Bytes()
 }
 
 type routerTestDTO struct {
@@ -0,0 +1,148 @@
+package easierweb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// server-sent events (SSE)
+
+// SSEEvent the fields are written in the text/event-stream format, empty fields are omitted
+type SSEEvent struct {
+	ID    string
+	Event string
+	// multi-line data is split into multiple data lines
+	Data string
+	// reconnection time of the client, it is sent in milliseconds
+	Retry time.Duration
+}
+
+// Bytes the event frame, example: id: 1\nevent: tick\ndata: hello\n\n
+func (e SSEEvent) Bytes() []byte {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + sseLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + sseLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	data := strings.ReplaceAll(e.Data, "\r\n", "\n")
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return []byte(b.String())
+}
+
+// the id and event fields cannot contain line breaks
+func sseLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// Push write the raw message to the stream and flush it, the message is written as is
+func (c *Context) Push(msg string) error {
+	return c.pushRaw(msg)
+}
+
+// PushEvent write the event to the stream and flush it
+func (c *Context) PushEvent(event SSEEvent) error {
+	return c.pushRaw(string(event.Bytes()))
+}
+
+// PushData write an unnamed event with the data
+func (c *Context) PushData(data string) error {
+	return c.PushEvent(SSEEvent{Data: data})
+}
+
+// PushJSON write an event with the json data, the event name can be empty
+func (c *Context) PushJSON(event string, obj any) error {
+	marshal, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.PushEvent(SSEEvent{Event: event, Data: string(marshal)})
+}
+
+// PushComment write a comment line, it is ignored by the clients and usually used to keep the connection alive
+func (c *Context) PushComment(comment string) error {
+	return c.pushRaw(": " + sseLine(comment) + "\n\n")
+}
+
+// LastEventID the id of the last event received by the client, it is sent by the client when reconnecting
+func (c *Context) LastEventID() string {
+	if c.Request == nil {
+		return ""
+	}
+	return c.Request.Header.Get("Last-Event-ID")
+}
+
+// Done closed when the client disconnects (or the server shuts down), SSE handles can select on it and exit
+func (c *Context) Done() <-chan struct{} {
+	return c.Request.Context().Done()
+}
+
+// Disconnected check whether the client has disconnected
+func (c *Context) Disconnected() bool {
+	select {
+	case <-c.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Context) pushRaw(msg string) error {
+	if c.Flusher == nil {
+		return fmt.Errorf("%s is not a server-sent events route", c.Route)
+	}
+	if c.Disconnected() {
+		return c.Request.Context().Err()
+	}
+	if c.pushMutex == nil {
+		c.pushMutex = &sync.Mutex{}
+	}
+	c.pushMutex.Lock()
+	defer c.pushMutex.Unlock()
+	_, err := io.WriteString(c.ResponseWriter, msg)
+	if err != nil {
+		return err
+	}
+	c.Flusher.Flush()
+	return nil
+}
+
+// keepAlive write comment lines periodically until the returned stop function is called
+func (c *Context) keepAlive(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-c.Done():
+				return
+			case <-ticker.C:
+				if c.PushComment("keepalive") != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
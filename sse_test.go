@@ -0,0 +1,111 @@
+package easierweb
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dpwgc/easierweb/easierwebtest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// server-sent events test
+
+func TestSSEEvent(t *testing.T) {
+
+	fmt.Println("\n[TestSSEEvent] start")
+
+	cases := []struct {
+		event SSEEvent
+		frame string
+	}{
+		{SSEEvent{Data: "hello"}, "data: hello\n\n"},
+		{SSEEvent{ID: "1", Event: "tick", Data: "hello\nworld", Retry: 3 * time.Second}, "id: 1\nevent: tick\nretry: 3000\ndata: hello\ndata: world\n\n"},
+		{SSEEvent{Event: "a\nb", Data: "100%\r\n"}, "event: ab\ndata: 100%\ndata: \n\n"},
+	}
+	for _, c := range cases {
+		frame := string(c.event.Bytes())
+		if frame != c.frame {
+			t.Errorf("unexpected sse frame: %q, expected: %q", frame, c.frame)
+		}
+	}
+}
+
+func TestSSE(t *testing.T) {
+
+	fmt.Println("\n[TestSSE] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test",
+		CloseConsolePrint: true,
+	})
+	router.SSE("/sse", func(ctx *Context) {
+		_ = ctx.Push("data: 100%s\n\n")
+		_ = ctx.PushEvent(SSEEvent{ID: "2", Event: "last", Data: ctx.LastEventID()})
+		_ = ctx.PushJSON("user", map[string]any{"name": "easier"})
+	})
+
+	tc := easierwebtest.New(router)
+	defer tc.Close()
+
+	stream, err := tc.GET("/test/sse").Header("Last-Event-ID", "1").SSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	expected := []easierwebtest.SSEEvent{
+		{Data: "100%s"},
+		{ID: "2", Event: "last", Data: "1"},
+		{Event: "user", Data: `{"name":"easier"}`},
+	}
+	for _, e := range expected {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("[TestSSE] event:", *event)
+		if *event != e {
+			t.Errorf("unexpected sse event: %+v, expected: %+v", *event, e)
+		}
+	}
+}
+
+func TestSSEKeepAlive(t *testing.T) {
+
+	fmt.Println("\n[TestSSEKeepAlive] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test",
+		CloseConsolePrint: true,
+	})
+	done := make(chan bool, 1)
+	router.SSE("/sse", func(ctx *Context) {
+		<-ctx.Done()
+		done <- ctx.Disconnected()
+	}).With(RouteOptions{SSEKeepAlive: 20 * time.Millisecond})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/test/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	fmt.Println("[TestSSEKeepAlive] line:", strings.TrimSpace(line))
+	if err != nil || line != ": keepalive\n" {
+		t.Fatalf("unexpected keepalive line: %q %v", line, err)
+	}
+	_ = res.Body.Close()
+
+	select {
+	case disconnected := <-done:
+		if !disconnected {
+			t.Error("the disconnection is not detected")
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("the handle does not exit after the client disconnects")
+	}
+}
@@ -0,0 +1,71 @@
+package easierweb
+
+import (
+	"reflect"
+)
+
+// generic typed usage function
+// like easier usage, but the handle signature is checked at compile time and called without reflection
+
+// Registrar *Router or *Group
+type Registrar interface {
+	planAPI(method, path string, plan *easyPlan, middlewares ...Handle)
+}
+
+func TypedGET[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodGET, path, handle, middlewares...)
+}
+
+func TypedHEAD[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodHEAD, path, handle, middlewares...)
+}
+
+func TypedOPTIONS[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodOPTIONS, path, handle, middlewares...)
+}
+
+func TypedPOST[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodPOST, path, handle, middlewares...)
+}
+
+func TypedPUT[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodPUT, path, handle, middlewares...)
+}
+
+func TypedPATCH[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodPATCH, path, handle, middlewares...)
+}
+
+func TypedDELETE[Req, Res any, R Registrar](r R, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	return TypedAPI(r, MethodDELETE, path, handle, middlewares...)
+}
+
+func TypedAPI[Req, Res any, R Registrar](r R, method, path string, handle func(ctx *Context, request Req) (*Res, error), middlewares ...Handle) R {
+	r.planAPI(method, path, newTypedPlan(handle), middlewares...)
+	return r
+}
+
+// bind and respond through the same request handle and response handle as the easy handles
+func newTypedPlan[Req, Res any](handle func(ctx *Context, request Req) (*Res, error)) *easyPlan {
+	return &easyPlan{
+		fn:       reflect.ValueOf(handle),
+		funcName: funcName(handle),
+		reqType:  reflect.TypeOf((*Req)(nil)).Elem(),
+		resType:  reflect.TypeOf((*Res)(nil)),
+		errIndex: 1,
+		invoke: func(r *Router, ctx *Context) {
+			var request Req
+			err := r.bindRequest(ctx, &request)
+			if err != nil {
+				ctx.responseHandle(ctx, nil, err)
+				return
+			}
+			res, err := handle(ctx, request)
+			if res == nil {
+				ctx.responseHandle(ctx, nil, err)
+				return
+			}
+			ctx.responseHandle(ctx, *res, err)
+		},
+	}
+}
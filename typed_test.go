@@ -0,0 +1,63 @@
+package easierweb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// typed handle test
+
+func TestTyped(t *testing.T) {
+
+	fmt.Println("\n[TestTyped] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test/typed",
+		CloseConsolePrint: true,
+	})
+
+	TypedPOST(router, "/post", typedTestSaveAPI)
+	TypedGET(router, "/get/:id", typedTestSaveAPI)
+
+	group := router.Group("/group")
+	TypedPUT(group, "/put/:id", typedTestSaveAPI)
+
+	cases := []struct {
+		method string
+		uri    string
+		body   string
+		code   int
+	}{
+		{"POST", "/post", "{\"int64\":3,\"string\":\"test\"}", http.StatusOK},
+		{"POST", "/post", "{\"int64\":\"abc\"}", http.StatusUnprocessableEntity},
+		{"GET", "/get/1?int64=3", "", http.StatusOK},
+		{"PUT", "/group/put/1", "{\"int64\":3}", http.StatusOK},
+		{"PUT", "/group/put/1", "{\"int64\":0}", http.StatusNoContent},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, "/test/typed"+c.uri, strings.NewReader(c.body))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		fmt.Printf("[TestTyped] %s %s response code: %v, data -> %s \n", c.method, c.uri, res.Code, res.Body.String())
+		if res.Code != c.code {
+			t.Fatal("typed handle status code error:", c.uri, res.Code)
+		}
+	}
+
+	if router.OpenAPI().Paths["/test/typed/group/put/{id}"]["put"] == nil {
+		t.Fatal("typed handle openapi error")
+	}
+
+	fmt.Println("\n[TestTyped] end")
+}
+
+func typedTestSaveAPI(ctx *Context, dto routerTestDTO) (*routerTestDTO, error) {
+	if dto.Int64 == 0 {
+		return nil, nil
+	}
+	return &dto, nil
+}
@@ -0,0 +1,281 @@
+package easierweb
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Validator interface {
+	Validate(obj any) error
+}
+
+// validators implementing it check the request types when the handles are registered, panic at startup if an error is returned
+type typeChecker interface {
+	Check(t reflect.Type) error
+}
+
+type FieldError struct {
+	Field   string `json:"field" xml:"Field" yaml:"field"`
+	Rule    string `json:"rule" xml:"Rule" yaml:"rule"`
+	Param   string `json:"param,omitempty" xml:"Param,omitempty" yaml:"param,omitempty"`
+	Message string `json:"message" xml:"Message" yaml:"message"`
+}
+
+type ValidationError struct {
+	Msg    string        `json:"msg" xml:"Msg" yaml:"msg"`
+	Fields []*FieldError `json:"fields" xml:"Fields>Field" yaml:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// TagValidator validate struct fields according to the validate tag, nested structs and slices are also validated
+// example: `validate:"required,min=1,max=64"`, `validate:"omitempty,email"`, `validate:"oneof=a b c"`
+type TagValidator struct{}
+
+func (v TagValidator) Validate(obj any) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	var fields []*FieldError
+	validateValue(value, "", &fields)
+	if len(fields) > 0 {
+		return &ValidationError{
+			Msg:    "validation failed",
+			Fields: fields,
+		}
+	}
+	return nil
+}
+
+// Check check the validate tags of the type, return an error if a rule is unknown or its parameter is invalid
+// the router checks the request types of the easy and typed handles at registration
+func (v TagValidator) Check(t reflect.Type) error {
+	return checkType(t, map[reflect.Type]bool{})
+}
+
+func checkType(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || visited[t] {
+		return nil
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag := f.Tag.Get("validate"); tag != "" && tag != "-" {
+			for _, rule := range strings.Split(tag, ",") {
+				rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+				err := checkRule(rule, param)
+				if err != nil {
+					return fmt.Errorf("%s.%s: %s", t, f.Name, err)
+				}
+			}
+		}
+		err := checkType(f.Type, visited)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkRule(rule, param string) error {
+	switch rule {
+	case "", "omitempty", "required", "email":
+		return nil
+	case "min", "max", "len":
+		_, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid validate rule parameter: %s=%s", rule, param)
+		}
+		return nil
+	case "oneof":
+		if strings.TrimSpace(param) == "" {
+			return fmt.Errorf("invalid validate rule parameter: %s=%s", rule, param)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown validate rule: %s", rule)
+	}
+}
+
+func validateValue(value reflect.Value, path string, fields *[]*FieldError) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return
+		}
+		validateStruct(value, path, fields)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i), fields)
+		}
+	}
+}
+
+func validateStruct(value reflect.Value, path string, fields *[]*FieldError) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("validate") == "" {
+			validateValue(value.Field(i), path, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := validateFieldName(f)
+		if path != "" {
+			name = path + "." + name
+		}
+		fieldValue := value.Field(i)
+		if tag := f.Tag.Get("validate"); tag != "" && tag != "-" {
+			fe := validateRules(fieldValue, name, tag)
+			if fe != nil {
+				*fields = append(*fields, fe)
+				continue
+			}
+		}
+		validateValue(fieldValue, name, fields)
+	}
+}
+
+func validateFieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "mapstructure"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// check the rules of a field in order, return the first rule that fails
+func validateRules(value reflect.Value, name, tag string) *FieldError {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			if strings.Contains(","+tag+",", ",required,") {
+				return &FieldError{Field: name, Rule: "required", Message: name + " is required"}
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		ok := true
+		message := ""
+		switch rule {
+		case "":
+			continue
+		case "omitempty":
+			if validateIsEmpty(value) {
+				return nil
+			}
+			continue
+		case "required":
+			ok = !validateIsEmpty(value)
+			message = name + " is required"
+		case "min":
+			ok = validateSize(value, param, func(size, limit float64) bool { return size >= limit })
+			message = fmt.Sprintf("%s must be at least %s%s", name, param, validateSizeUnit(value))
+		case "max":
+			ok = validateSize(value, param, func(size, limit float64) bool { return size <= limit })
+			message = fmt.Sprintf("%s must be at most %s%s", name, param, validateSizeUnit(value))
+		case "len":
+			ok = validateSize(value, param, func(size, limit float64) bool { return size == limit })
+			message = fmt.Sprintf("%s must be exactly %s%s", name, param, validateSizeUnit(value))
+		case "email":
+			ok = value.Kind() == reflect.String && validateEmail(value.String())
+			message = name + " must be a valid email address"
+		case "oneof":
+			ok = false
+			s := fmt.Sprint(value.Interface())
+			for _, option := range strings.Fields(param) {
+				if s == option {
+					ok = true
+					break
+				}
+			}
+			message = fmt.Sprintf("%s must be one of [%s]", name, param)
+		default:
+			// unknown rules are reported by Check at registration, they are ignored if the type is not checked
+			continue
+		}
+		if !ok {
+			return &FieldError{Field: name, Rule: rule, Param: param, Message: message}
+		}
+	}
+	return nil
+}
+
+func validateIsEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// strings, slices and maps compare the length, numbers compare the value
+func validateSize(value reflect.Value, param string, compare func(size, limit float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		// invalid parameters are reported by Check at registration, the rule is ignored if the type is not checked
+		return true
+	}
+	var size float64
+	switch value.Kind() {
+	case reflect.String:
+		size = float64(utf8.RuneCountInString(value.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size = float64(value.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = value.Float()
+	default:
+		return false
+	}
+	return compare(size, limit)
+}
+
+func validateSizeUnit(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+func validateEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
@@ -0,0 +1,125 @@
+package easierweb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// validator test
+
+func TestValidator(t *testing.T) {
+
+	fmt.Println("\n[TestValidator] start")
+
+	validator := TagValidator{}
+
+	err := validator.Validate(&validatorTestDTO{
+		Name:  "hello",
+		Email: "hello@example.com",
+		Type:  "a",
+		Items: []validatorTestItemDTO{{ID: 1}},
+		Inner: validatorTestItemDTO{ID: 2},
+	})
+	if err != nil {
+		t.Fatal("validate error:", err)
+	}
+
+	err = validator.Validate(&validatorTestDTO{
+		Name:  "",
+		Email: "hello",
+		Type:  "c",
+		Items: []validatorTestItemDTO{{ID: 0}},
+		Inner: validatorTestItemDTO{ID: 100},
+	})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatal("validate error type error:", err)
+	}
+	fmt.Println("[TestValidator] validate error ->", err)
+
+	fields := map[string]string{}
+	for _, f := range validationErr.Fields {
+		fields[f.Field] = f.Rule
+	}
+	expected := map[string]string{
+		"name":        "required",
+		"email":       "email",
+		"type":        "oneof",
+		"items[0].id": "min",
+		"inner.id":    "max",
+	}
+	if len(fields) != len(expected) {
+		t.Fatal("validate fields error:", fields)
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Fatal("validate field error:", k, fields[k])
+		}
+	}
+
+	fmt.Println("\n[TestValidator] end")
+}
+
+func TestValidatorCheck(t *testing.T) {
+
+	fmt.Println("\n[TestValidatorCheck] start")
+
+	type unknownRuleDTO struct {
+		Page int `json:"page" validate:"gte=0"`
+	}
+	type invalidParamDTO struct {
+		Items []validatorTestItemDTO `json:"items"`
+		Name  string                 `json:"name" validate:"max=abc"`
+	}
+
+	// the validator is opt-in, the tags of other validators (e.g. go-playground/validator) do not affect the default router
+	New(RouterOptions{
+		CloseConsolePrint: true,
+	}).EasyPOST("/unknown", func(ctx *Context, request unknownRuleDTO) error { return nil })
+
+	router := New(RouterOptions{
+		Validator:         TagValidator{},
+		CloseConsolePrint: true,
+	})
+	router.EasyPOST("/valid", func(ctx *Context, request validatorTestDTO) error { return nil })
+
+	invalid := []any{
+		func(ctx *Context, request unknownRuleDTO) error { return nil },
+		func(ctx *Context, request *invalidParamDTO) error { return nil },
+	}
+	for i, v := range invalid {
+		func() {
+			defer func() {
+				err := recover()
+				fmt.Println("[TestValidatorCheck] register panic ->", err)
+				if err == nil {
+					t.Error("the invalid validate tags are not reported at registration")
+				}
+			}()
+			router.EasyPOST(fmt.Sprintf("/invalid/%d", i), v)
+		}()
+	}
+
+	// the unchecked types do not panic, the invalid rules are ignored
+	err := TagValidator{}.Validate(&unknownRuleDTO{Page: -1})
+	if err != nil {
+		t.Error("unknown rule validate error:", err)
+	}
+	err = TagValidator{}.Validate(&invalidParamDTO{Name: "hello"})
+	if err != nil {
+		t.Error("invalid parameter validate error:", err)
+	}
+}
+
+type validatorTestDTO struct {
+	Name  string                 `json:"name" validate:"required,min=1,max=64"`
+	Email string                 `json:"email" validate:"omitempty,email"`
+	Type  string                 `json:"type" validate:"oneof=a b"`
+	Items []validatorTestItemDTO `json:"items" validate:"required"`
+	Inner validatorTestItemDTO   `json:"inner"`
+}
+
+type validatorTestItemDTO struct {
+	ID int64 `json:"id" validate:"min=1,max=10"`
+}
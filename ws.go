@@ -0,0 +1,159 @@
+package easierweb
+
+import (
+	"errors"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// websocket handshake and connection options
+// there is no compression option, golang.org/x/net/websocket cannot negotiate permessage-deflate
+
+type WSOptions struct {
+	// allowed origins of the browser clients, exact (https://example.com) or wildcard (https://*.example.com, *)
+	// default: same origin, the requests without the Origin header (non-browser clients) are always allowed
+	AllowedOrigins []string
+	// custom origin check, override AllowedOrigins
+	CheckOrigin func(req *http.Request) bool
+	// supported subprotocols in order of preference, the first one offered by the client is selected
+	Subprotocols []string
+	// max size of the received messages in bytes, default: 32 MB (websocket.DefaultMaxPayloadBytes)
+	MaxMessageSize int
+	// deadline of each receive and send, zero for no deadline
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// interval of the ping frames, zero to disable
+	PingInterval time.Duration
+	// the connection is deemed dead and closed if nothing (messages, pongs) is received within the timeout, zero to disable
+	// the pongs are read by ctx.Receive, so the handle must keep receiving messages, or set PushOnly
+	IdleTimeout time.Duration
+	// the handle only sends messages, the received frames (messages, pongs, pings) are read and discarded by a background reader
+	// it keeps the IdleTimeout of the handles that never receive, ctx.Receive returns ErrWSPushOnly
+	PushOnly bool
+	// called when the connection is deemed dead, before it is closed
+	OnDead func(ctx *Context)
+}
+
+var ErrWSOriginNotAllowed = errors.New("websocket origin not allowed")
+
+var ErrWSPushOnly = errors.New("websocket connection is push-only, the received messages are discarded")
+
+// wsOptions the route options override the router options
+func (r *Router) wsOptions(info *routeInfo) WSOptions {
+	if info.options.WS != nil {
+		return *info.options.WS
+	}
+	return r.ws
+}
+
+// wsServer the options are read on each request, so they can be changed by With after the registration
+func (r *Router) wsServer(info *routeInfo, handler websocket.Handler) websocket.Server {
+	opts := r.wsOptions(info)
+	return websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			if opts.MaxMessageSize > 0 {
+				ws.MaxPayloadBytes = opts.MaxMessageSize
+			}
+			if opts.PushOnly {
+				go discardReceived(ws)
+			}
+			handler(ws)
+		},
+		// the handshake fails with 403 if an error is returned
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			if !opts.allowOrigin(req) {
+				return ErrWSOriginNotAllowed
+			}
+			if len(opts.Subprotocols) > 0 {
+				config.Protocol = selectSubprotocol(opts.Subprotocols, config.Protocol)
+			}
+			return nil
+		},
+	}
+}
+
+func (o WSOptions) allowOrigin(req *http.Request) bool {
+	if o.CheckOrigin != nil {
+		return o.CheckOrigin(req)
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(o.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, req.Host)
+	}
+	for _, pattern := range o.AllowedOrigins {
+		if matchOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin the pattern can contain one wildcard, e.g. https://*.example.com
+func matchOrigin(pattern, origin string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+	if pattern == "*" || pattern == origin {
+		return true
+	}
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return false
+	}
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// selectSubprotocol no subprotocol is selected if the client offers none of the supported subprotocols
+func selectSubprotocol(supported, offered []string) []string {
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return []string{s}
+			}
+		}
+	}
+	return nil
+}
+
+// Subprotocol the negotiated websocket subprotocol, empty if none is selected
+func (c *Context) Subprotocol() string {
+	if c.WebsocketConn == nil {
+		return ""
+	}
+	protocols := c.WebsocketConn.Config().Protocol
+	if len(protocols) == 0 {
+		return ""
+	}
+	return protocols[0]
+}
+
+func (c *Context) setReadDeadline() error {
+	if c.wsPushOnly {
+		return ErrWSPushOnly
+	}
+	if c.wsReadTimeout <= 0 {
+		return nil
+	}
+	return c.WebsocketConn.SetReadDeadline(time.Now().Add(c.wsReadTimeout))
+}
+
+func (c *Context) setWriteDeadline() error {
+	if c.wsWriteTimeout <= 0 {
+		return nil
+	}
+	return c.WebsocketConn.SetWriteDeadline(time.Now().Add(c.wsWriteTimeout))
+}
+
+// discardReceived read the frames of a push-only connection until it is closed
+// the pings are answered and the pongs are tracked by the heartbeat while reading
+func discardReceived(ws *websocket.Conn) {
+	var buf []byte
+	for websocket.Message.Receive(ws, &buf) == nil {
+	}
+}
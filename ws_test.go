@@ -0,0 +1,157 @@
+package easierweb
+
+import (
+	"fmt"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// websocket options test
+
+func TestWSOptions(t *testing.T) {
+
+	fmt.Println("\n[TestWSOptions] start")
+
+	router := New(RouterOptions{
+		RootPath:          "/test",
+		CloseConsolePrint: true,
+	})
+	echo := func(ctx *Context) {
+		for {
+			msg, err := ctx.ReceiveString()
+			if err != nil {
+				_ = ctx.SendString("error: " + err.Error())
+				return
+			}
+			_ = ctx.SendString(ctx.Subprotocol() + " " + msg)
+		}
+	}
+	router.WS("/same", echo)
+	router.WS("/chat", echo).With(RouteOptions{WS: &WSOptions{
+		AllowedOrigins: []string{"https://*.example.com", "https://example.org"},
+		Subprotocols:   []string{"chat.v2", "chat.v1"},
+		MaxMessageSize: 8,
+	}})
+	group := router.Group("/slow").Options(RouteOptions{WS: &WSOptions{
+		CheckOrigin: func(req *http.Request) bool {
+			return req.Header.Get("X-Token") == "ok"
+		},
+		ReadTimeout: 50 * time.Millisecond,
+	}})
+	group.WS("/ws", echo)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/test"
+
+	dial := func(path, origin string, header http.Header, protocols ...string) (*websocket.Conn, error) {
+		config, err := websocket.NewConfig(wsURL+path, origin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Protocol = protocols
+		if header != nil {
+			config.Header = header
+		}
+		return websocket.DialConfig(config)
+	}
+	echoTest := func(ws *websocket.Conn, msg, expected string) {
+		err := websocket.Message.Send(ws, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reply string
+		err = websocket.Message.Receive(ws, &reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("[TestWSOptions] reply:", reply)
+		if reply != expected {
+			t.Errorf("unexpected reply: %s, expected: %s", reply, expected)
+		}
+	}
+
+	origins := []struct {
+		path   string
+		origin string
+		header http.Header
+		ok     bool
+	}{
+		{"/same", server.URL, nil, true},
+		{"/same", "https://evil.com", nil, false},
+		{"/chat", "https://app.example.com", nil, true},
+		{"/chat", "https://example.org", nil, true},
+		{"/chat", "https://example.com", nil, false},
+		{"/chat", "https://evil.com", nil, false},
+		{"/slow/ws", "https://evil.com", http.Header{"X-Token": {"ok"}}, true},
+		{"/slow/ws", server.URL, nil, false},
+	}
+	for _, c := range origins {
+		ws, err := dial(c.path, c.origin, c.header)
+		if (err == nil) != c.ok {
+			t.Errorf("origin %s of %s, expected allowed: %v, error: %v", c.origin, c.path, c.ok, err)
+		}
+		if ws != nil {
+			_ = ws.Close()
+		}
+	}
+
+	// subprotocol negotiation
+	ws, err := dial("/chat", "https://app.example.com", nil, "chat.v1", "chat.v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	echoTest(ws, "hello", "chat.v2 hello")
+	// max message size
+	echoTest(ws, "hello world", "error: "+websocket.ErrFrameTooLarge.Error())
+	_ = ws.Close()
+
+	ws, err = dial("/chat", "https://app.example.com", nil, "graphql-ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	echoTest(ws, "hello", " hello")
+	_ = ws.Close()
+
+	// read timeout
+	ws, err = dial("/slow/ws", server.URL, http.Header{"X-Token": {"ok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	var reply string
+	err = websocket.Message.Receive(ws, &reply)
+	fmt.Println("[TestWSOptions] timeout reply:", reply)
+	if err != nil || !strings.Contains(reply, "timeout") {
+		t.Errorf("unexpected timeout reply: %s %v", reply, err)
+	}
+}
+
+func TestMatchOrigin(t *testing.T) {
+
+	fmt.Println("\n[TestMatchOrigin] start")
+
+	cases := []struct {
+		pattern string
+		origin  string
+		ok      bool
+	}{
+		{"*", "https://a.com", true},
+		{"https://a.com", "https://A.com", true},
+		{"https://a.com/", "https://a.com", true},
+		{"https://a.com", "http://a.com", false},
+		{"https://*.a.com", "https://x.y.a.com", true},
+		{"https://*.a.com", "https://.a.com", false},
+		{"https://*.a.com", "https://xa.com", false},
+		{"http://localhost:*", "http://localhost:8080", true},
+	}
+	for _, c := range cases {
+		if matchOrigin(c.pattern, c.origin) != c.ok {
+			t.Errorf("match origin %s %s, expected: %v", c.pattern, c.origin, c.ok)
+		}
+	}
+}